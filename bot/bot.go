@@ -5,6 +5,7 @@ import (
     "hometown-bot/commands/lobby"
     "hometown-bot/commands/message"
     "hometown-bot/commands/reset"
+    "hometown-bot/commands/room"
     "hometown-bot/log"
     "hometown-bot/repository"
     "os"
@@ -16,21 +17,48 @@ import (
 // Token - discord bot API
 var Token string
 
+// Presences - whether the privileged presence intent is requested, it has to be enabled for the application
+var Presences bool
+
 type Bot struct {
-    channelRepository        repository.ChannelRepository
-    channelMembersRepository repository.ChannelMembersRepository
-    lobbyRepository          repository.LobbyRepository
+    channelRepository         repository.ChannelRepository
+    channelMembersRepository  repository.ChannelMembersRepository
+    channelPermitsRepository  repository.ChannelPermitsRepository
+    lobbyRepository           repository.LobbyRepository
+    userPreferencesRepository repository.UserPreferencesRepository
+    guildRepository           repository.GuildRepository
+    lobbyRolesRepository      repository.LobbyRolesRepository
+    roomBansRepository        repository.RoomBansRepository
+    lobbyOverwritesRepository repository.LobbyOverwritesRepository
+    lobbyOverflowsRepository  repository.LobbyOverflowsRepository
+    transactor                repository.Transactor
 }
 
 func Create(
     channelRepository repository.ChannelRepository,
     channelMembersRepository repository.ChannelMembersRepository,
+    channelPermitsRepository repository.ChannelPermitsRepository,
     lobbyRepository repository.LobbyRepository,
+    userPreferencesRepository repository.UserPreferencesRepository,
+    guildRepository repository.GuildRepository,
+    lobbyRolesRepository repository.LobbyRolesRepository,
+    roomBansRepository repository.RoomBansRepository,
+    lobbyOverwritesRepository repository.LobbyOverwritesRepository,
+    lobbyOverflowsRepository repository.LobbyOverflowsRepository,
+    transactor repository.Transactor,
 ) *Bot {
     return &Bot{
-        channelRepository:        channelRepository,
-        channelMembersRepository: channelMembersRepository,
-        lobbyRepository:          lobbyRepository,
+        channelRepository:         channelRepository,
+        channelMembersRepository:  channelMembersRepository,
+        channelPermitsRepository:  channelPermitsRepository,
+        lobbyRepository:           lobbyRepository,
+        userPreferencesRepository: userPreferencesRepository,
+        guildRepository:           guildRepository,
+        lobbyRolesRepository:      lobbyRolesRepository,
+        roomBansRepository:        roomBansRepository,
+        lobbyOverwritesRepository: lobbyOverwritesRepository,
+        lobbyOverflowsRepository:  lobbyOverflowsRepository,
+        transactor:                transactor,
     }
 }
 
@@ -41,16 +69,48 @@ func (bot *Bot) Run() error {
         return fmt.Errorf("unable to create a new bot session: %w", err)
     }
 
+    // Presences are required for %game% placeholder and activity renaming, the gateway refuses the privileged
+    // intent unless it is enabled for the application
+    discord.Identify.Intents = discordgo.IntentsAllWithoutPrivileged
+    if Presences {
+        discord.Identify.Intents |= discordgo.IntentsGuildPresences
+    }
+
     log.Debug().Println("bot: load commands")
-    lobbyCommands := lobby.New(bot.channelRepository, bot.channelMembersRepository, bot.lobbyRepository)
+    lobbyCommands := lobby.New(
+        bot.channelRepository,
+        bot.channelMembersRepository,
+        bot.channelPermitsRepository,
+        bot.lobbyRepository,
+        bot.userPreferencesRepository,
+        bot.guildRepository,
+        bot.lobbyRolesRepository,
+        bot.roomBansRepository,
+        bot.lobbyOverwritesRepository,
+        bot.lobbyOverflowsRepository,
+        bot.transactor,
+        Presences,
+    )
     resetCommands := reset.New(bot.channelRepository, bot.lobbyRepository)
     messageCommands := message.New()
+    roomCommands := room.New(
+        bot.channelRepository,
+        bot.channelPermitsRepository,
+        bot.userPreferencesRepository,
+        bot.roomBansRepository,
+    )
 
     log.Debug().Println("bot: attach handlers for commands")
     discord.AddHandler(lobbyCommands.HandleSlashCommands)
     discord.AddHandler(resetCommands.HandleSlashCommands)
     discord.AddHandler(lobbyCommands.HandleVoiceUpdates)
+    discord.AddHandler(lobbyCommands.HandleGuildCreate)
+    if Presences {
+        discord.AddHandler(lobbyCommands.HandlePresenceUpdate)
+    }
+    discord.AddHandler(lobbyCommands.HandleComponents)
     discord.AddHandler(messageCommands.HandleSlashCommands)
+    discord.AddHandler(roomCommands.HandleSlashCommands)
 
     log.Debug().Println("bot: establish socket connection")
     if err := discord.Open(); err != nil {
@@ -85,6 +145,7 @@ func (bot *Bot) Run() error {
 func createCommands(discord *discordgo.Session) ([]*discordgo.ApplicationCommand, error) {
     joinedCommands := append(lobby.Commands, reset.Commands...)
     joinedCommands = append(joinedCommands, message.Commands...)
+    joinedCommands = append(joinedCommands, room.Commands...)
 
     registeredCommands := make([]*discordgo.ApplicationCommand, len(joinedCommands))
     for i, v := range joinedCommands {
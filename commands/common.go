@@ -5,6 +5,8 @@ import (
     "github.com/bwmarrin/discordgo"
     "hometown-bot/model"
     "hometown-bot/repository"
+    "hometown-bot/util/discord"
+    "strings"
 )
 
 func HasLobby(
@@ -21,3 +23,262 @@ func HasLobby(
 
     return model.CommandResponse{}, nil
 }
+
+// HasLobbyAccess verifies that the member roles allow joining the lobby.
+// Denied roles always win, and lobbies with allowed roles are open only to their holders.
+func HasLobbyAccess(
+    repository repository.LobbyRolesRepository,
+    lobbyId string,
+    member *discordgo.Member,
+) (model.CommandResponse, error) {
+    roles, err := repository.GetLobbyRoles(lobbyId)
+    if err != nil {
+        return model.CommandError("Unable to verify your roles!"), fmt.Errorf("db: %w", err)
+    }
+
+    memberRoles := make(map[string]bool, len(member.Roles))
+    for _, roleId := range member.Roles {
+        memberRoles[roleId] = true
+    }
+
+    var allowedRoles []string
+    isAllowed := false
+    for _, role := range roles {
+        if !role.Allowed && memberRoles[role.RoleID] {
+            return model.CommandWarning(
+                    fmt.Sprintf("Members with <@&%s> role cannot create rooms in <#%s>.", role.RoleID, lobbyId),
+                ),
+                fmt.Errorf("member %s has denied role %s in lobby %s", member.User.ID, role.RoleID, lobbyId)
+        }
+
+        if role.Allowed {
+            allowedRoles = append(allowedRoles, fmt.Sprintf("<@&%s>", role.RoleID))
+            isAllowed = isAllowed || memberRoles[role.RoleID]
+        }
+    }
+
+    if len(allowedRoles) > 0 && !isAllowed {
+        return model.CommandWarning(
+                fmt.Sprintf("Rooms in <#%s> are only for %s.", lobbyId, strings.Join(allowedRoles, ", ")),
+            ),
+            fmt.Errorf("member %s has no allowed role in lobby %s", member.User.ID, lobbyId)
+    }
+
+    return model.CommandResponse{}, nil
+}
+
+func GetRoom(
+    s *discordgo.Session,
+    repository repository.ChannelRepository,
+    guildId string,
+    userId string,
+) (model.Channel, model.CommandResponse, error) {
+    voiceState, err := s.State.VoiceState(guildId, userId)
+    if err != nil {
+        return model.Channel{},
+            model.CommandWarning("You are not connected to a room!"),
+            fmt.Errorf("state: user %s is not connected to a voice channel: %w", userId, err)
+    }
+
+    channel, err := repository.GetChannel(voiceState.ChannelID, guildId)
+    if err != nil {
+        return model.Channel{},
+            model.CommandWarning("You are not connected to a room!"),
+            fmt.Errorf("db: %s is not a room: %w", voiceState.ChannelID, err)
+    }
+
+    return channel, model.CommandResponse{}, nil
+}
+
+func GetOwnedRoom(
+    s *discordgo.Session,
+    repository repository.ChannelRepository,
+    guildId string,
+    userId string,
+) (model.Channel, model.CommandResponse, error) {
+    channel, response, err := GetRoom(s, repository, guildId, userId)
+    if err != nil {
+        return model.Channel{}, response, err
+    }
+
+    if channel.OwnerID != userId {
+        return model.Channel{},
+            model.CommandWarning("Only the room owner can do this!"),
+            fmt.Errorf("user %s is not the owner of room %s", userId, channel.Id)
+    }
+
+    return channel, model.CommandResponse{}, nil
+}
+
+// TransferRoom makes newOwnerId the owner of the room, the previous owner loses their permissions.
+func TransferRoom(
+    s *discordgo.Session,
+    repository repository.ChannelRepository,
+    channel model.Channel,
+    newOwnerId string,
+) error {
+    if err := repository.SetChannelOwner(channel.Id, newOwnerId); err != nil {
+        return fmt.Errorf("db: %w", err)
+    }
+
+    if channel.OwnerID != "" {
+        if err := discord.MoveOverwrite(s, channel.Id, channel.OwnerID, newOwnerId); err != nil {
+            return fmt.Errorf("API: %w", err)
+        }
+    }
+
+    if _, err := s.ChannelMessageSend(
+        channel.Id,
+        fmt.Sprintf("<@%s> is now the owner of this room.", newOwnerId),
+    ); err != nil {
+        return fmt.Errorf("API: unable to notify room %s about the new owner: %w", channel.Id, err)
+    }
+
+    return nil
+}
+
+// SetRoomPrivacy updates @everyone permissions of the room, the owner always keeps access to it.
+func SetRoomPrivacy(
+    s *discordgo.Session,
+    repository repository.ChannelRepository,
+    guildId string,
+    channel model.Channel,
+    privacy model.Privacy,
+) error {
+    previousBits := privacyBits(channel.Privacy)
+    newBits := privacyBits(privacy)
+
+    // @everyone role has the same id as the guild
+    if staleBits := previousBits &^ newBits; staleBits != 0 {
+        if err := discord.ClearOverwrite(
+            s,
+            channel.Id,
+            guildId,
+            discordgo.PermissionOverwriteTypeRole,
+            staleBits,
+        ); err != nil {
+            return fmt.Errorf("API: %w", err)
+        }
+    }
+
+    if err := applyPrivacy(s, guildId, channel.Id, channel.OwnerID, privacy); err != nil {
+        return err
+    }
+
+    if err := repository.SetChannelPrivacy(channel.Id, privacy); err != nil {
+        return fmt.Errorf("db: %w", err)
+    }
+
+    return nil
+}
+
+// SetRoomPermit allows or denies the user to join the room regardless of its privacy.
+func SetRoomPermit(
+    s *discordgo.Session,
+    repository repository.ChannelPermitsRepository,
+    permit model.ChannelPermit,
+) error {
+    if err := applyPermit(s, permit); err != nil {
+        return err
+    }
+
+    if err := repository.SetChannelPermit(&permit); err != nil {
+        return fmt.Errorf("db: %w", err)
+    }
+
+    return nil
+}
+
+// ApplyRoomPermissions restores stored privacy and permits of the room in Discord.
+func ApplyRoomPermissions(
+    s *discordgo.Session,
+    guildId string,
+    channel model.Channel,
+    permits []model.ChannelPermit,
+) error {
+    if err := applyPrivacy(s, guildId, channel.Id, channel.OwnerID, channel.Privacy); err != nil {
+        return err
+    }
+
+    for _, permit := range permits {
+        if err := applyPermit(s, permit); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+func applyPrivacy(s *discordgo.Session, guildId string, channelId string, ownerId string, privacy model.Privacy) error {
+    bits := privacyBits(privacy)
+    if bits == 0 {
+        return nil
+    }
+
+    if ownerId != "" {
+        if err := discord.EditOverwrite(
+            s,
+            channelId,
+            ownerId,
+            discordgo.PermissionOverwriteTypeMember,
+            bits,
+            0,
+        ); err != nil {
+            return fmt.Errorf("API: %w", err)
+        }
+    }
+
+    if err := discord.EditOverwrite(
+        s,
+        channelId,
+        guildId,
+        discordgo.PermissionOverwriteTypeRole,
+        0,
+        bits,
+    ); err != nil {
+        return fmt.Errorf("API: %w", err)
+    }
+
+    return nil
+}
+
+func applyPermit(s *discordgo.Session, permit model.ChannelPermit) error {
+    var err error
+    if permit.Allowed {
+        err = discord.EditOverwrite(
+            s,
+            permit.ChannelID,
+            permit.UserID,
+            discordgo.PermissionOverwriteTypeMember,
+            discordgo.PermissionViewChannel|discordgo.PermissionVoiceConnect,
+            0,
+        )
+    } else {
+        // A previously permitted user loses the View allow too, otherwise they still see a hidden room
+        err = discord.DenyOverwrite(
+            s,
+            permit.ChannelID,
+            permit.UserID,
+            discordgo.PermissionOverwriteTypeMember,
+            discordgo.PermissionViewChannel,
+            discordgo.PermissionVoiceConnect,
+        )
+    }
+
+    if err != nil {
+        return fmt.Errorf("API: %w", err)
+    }
+
+    return nil
+}
+
+func privacyBits(privacy model.Privacy) int64 {
+    switch privacy {
+    case model.PrivacyLocked:
+        return discordgo.PermissionVoiceConnect
+    case model.PrivacyHidden:
+        return discordgo.PermissionViewChannel | discordgo.PermissionVoiceConnect
+    default:
+        return 0
+    }
+}
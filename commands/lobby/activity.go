@@ -0,0 +1,272 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/placeholder"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandActivity string = "activity" // Subcommand lobby activity
+)
+
+// Discord allows renaming a channel twice per 10 minutes
+var (
+    renameDebounce = 30 * time.Second // Quiet time after the last presence change before the room is renamed
+    renameInterval = 5 * time.Minute  // Minimal time between two renames of the same room
+)
+
+// renameHistory remembers when rooms were renamed after their activity.
+type renameHistory struct {
+    mu        sync.Mutex
+    renamedAt map[string]time.Time     // Room id to time of its last rename
+    names     map[string]activityNames // Room id to its names while it is named after a game
+}
+
+// activityNames tells the name given after the game from the one the room is restored to.
+type activityNames struct {
+    original string // Name before the room was named after the game
+    game     string // Name the bot gave after the game
+}
+
+/* ------ COMMANDS ------ */
+
+func getActivityCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandActivity,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Rename channels after the game most of their members are playing.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionBoolean,
+                Name:        optionEnabled,
+                Description: "Whether channels are renamed after the game.",
+                Required:    true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandActivity(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    enabled := options[0].Options[1].BoolValue()
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: activity command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Activity: sql.NullBool{
+            Valid: true,
+            Bool:  enabled,
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: activity command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update activity renaming for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: activity command: save activity %t for %s[%s]", enabled, channel.Name, lobby.Id)
+    if enabled && !lc.presences {
+        return model.CommandWarning(
+            fmt.Sprintf(
+                "Activity renaming is saved for \"%s\", but rooms keep their names, the bot runs without presences.",
+                channel.Name,
+            ),
+        )
+    }
+
+    if enabled {
+        return model.CommandSuccess(
+            fmt.Sprintf("Rooms of \"%s\" are now named after the game being played.", channel.Name),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("Rooms of \"%s\" are not renamed after the game anymore.", channel.Name),
+    )
+}
+
+/* ------ PRESENCE ------ */
+
+// HandlePresenceUpdate renames the room of the member once their game changes.
+func (lc *Command) HandlePresenceUpdate(s *discordgo.Session, event *discordgo.PresenceUpdate) {
+    if event.User == nil {
+        return
+    }
+
+    voiceState, err := s.State.VoiceState(event.GuildID, event.User.ID)
+    if err != nil || voiceState.ChannelID == "" {
+        return
+    }
+
+    channel, err := lc.channelRepository.GetChannel(voiceState.ChannelID, event.GuildID)
+    if err != nil {
+        return
+    }
+
+    lc.scheduleRename(s, event.GuildID, channel)
+}
+
+// scheduleRename debounces renames of the room, so that it is renamed once the activity settles down
+// and never more often than Discord allows.
+func (lc *Command) scheduleRename(s *discordgo.Session, guildId string, channel model.Channel) {
+    if !lc.presences {
+        return
+    }
+
+    l, err := lc.lobbyRepository.GetLobby(channel.ParentID, guildId)
+    if err != nil || !l.Activity.Valid || !l.Activity.Bool {
+        return
+    }
+
+    delay := renameDebounce
+
+    lc.history.mu.Lock()
+    if renamedAt, ok := lc.history.renamedAt[channel.Id]; ok {
+        if wait := time.Until(renamedAt.Add(renameInterval)); wait > delay {
+            delay = wait
+        }
+    }
+    lc.history.mu.Unlock()
+
+    log.Debug().Printf("activity: rename channel %s in %s", channel.Id, delay)
+    lc.renames.Schedule(channel.Id, delay, func() {
+        lc.renameAfterActivity(s, guildId, channel.Id)
+    })
+}
+
+// cancelRename forgets about the room once it is deleted.
+func (lc *Command) cancelRename(channelId string) {
+    lc.renames.Cancel(channelId)
+
+    lc.history.mu.Lock()
+    delete(lc.history.renamedAt, channelId)
+    delete(lc.history.names, channelId)
+    lc.history.mu.Unlock()
+}
+
+func (lc *Command) renameAfterActivity(s *discordgo.Session, guildId string, channelId string) {
+    channel, err := lc.channelRepository.GetChannel(channelId, guildId)
+    if err != nil {
+        log.Warn().Printf("activity: get channel: %v", err)
+        return
+    }
+
+    members, err := lc.channelMembersRepository.GetChannelMembers(guildId, channel.Id)
+    if err != nil {
+        log.Error().Printf("activity: get channel members: %v", err)
+        return
+    }
+
+    current, err := s.State.Channel(channel.Id)
+    if err != nil {
+        if current, err = s.Channel(channel.Id); err != nil {
+            log.Error().Printf("activity: API: unable to get channel %s: %v", channel.Id, err)
+            return
+        }
+    }
+
+    lc.history.mu.Lock()
+    names, isNamedAfterGame := lc.history.names[channel.Id]
+    lc.history.mu.Unlock()
+
+    // Names given by /room rename or saved preferences meanwhile are kept and restored later
+    if isNamedAfterGame && current.Name != names.game {
+        isNamedAfterGame = false
+    }
+
+    var name string
+    if game := lc.getCommonGame(s, guildId, members); game != "" {
+        name = placeholder.Render(placeholder.Game, placeholder.Values{Game: game})
+        if !isNamedAfterGame {
+            names.original = current.Name
+        }
+        names.game = name
+    } else {
+        // Nobody is playing anymore, the room gets back the name it had before, unless it was renamed since
+        lc.history.mu.Lock()
+        delete(lc.history.names, channel.Id)
+        lc.history.mu.Unlock()
+
+        if !isNamedAfterGame {
+            return
+        }
+
+        name = names.original
+    }
+
+    if current.Name == name {
+        return
+    }
+
+    log.Info().Printf("activity: rename channel %s to %s", channel.Id, name)
+    if _, err := s.ChannelEdit(channel.Id, &discordgo.ChannelEdit{Name: name}); err != nil {
+        log.Error().Printf("activity: API: unable to rename channel %s to %s: %v", channel.Id, name, err)
+        return
+    }
+
+    lc.history.mu.Lock()
+    if lc.history.renamedAt == nil {
+        lc.history.renamedAt = make(map[string]time.Time)
+    }
+    lc.history.renamedAt[channel.Id] = time.Now()
+
+    if name == names.game {
+        if lc.history.names == nil {
+            lc.history.names = make(map[string]activityNames)
+        }
+        lc.history.names[channel.Id] = names
+    }
+    lc.history.mu.Unlock()
+
+    if channel.TextID != "" {
+        if _, err := s.ChannelEdit(channel.TextID, &discordgo.ChannelEdit{Name: name}); err != nil {
+            log.Error().Printf("activity: API: unable to rename text channel %s to %s: %v", channel.TextID, name, err)
+        }
+    }
+}
+
+// getCommonGame returns the game most of the members are playing,
+// ties are resolved in favour of the member who joined first.
+func (lc *Command) getCommonGame(s *discordgo.Session, guildId string, userIds []string) string {
+    counts := make(map[string]int)
+    var games []string
+    for _, userId := range userIds {
+        game := lc.getGame(s, guildId, userId)
+        if game == "" {
+            continue
+        }
+
+        if counts[game] == 0 {
+            games = append(games, game)
+        }
+        counts[game]++
+    }
+
+    var commonGame string
+    for _, game := range games {
+        if counts[game] > counts[commonGame] {
+            commonGame = game
+        }
+    }
+
+    return commonGame
+}
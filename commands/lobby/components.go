@@ -0,0 +1,80 @@
+package lobby
+
+import (
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// componentHandler answers the component action of a feature, returns whether the components are answered for good
+// and whether the action is known at all.
+type componentHandler func(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    action string,
+    key string,
+) (model.CommandResponse, bool, bool)
+
+// createComponentHandlers maps prefixes of custom ids "<prefix>:<action>:<key>" to the features owning them.
+func (lc *Command) createComponentHandlers() map[string]componentHandler {
+    return map[string]componentHandler{
+        knockPrefix:  lc.handleKnockComponent,
+        importPrefix: lc.handleImportComponent,
+    }
+}
+
+// HandleComponents dispatches message components to the feature selected by the prefix of their custom id.
+func (lc *Command) HandleComponents(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    if i.Type != discordgo.InteractionMessageComponent {
+        return
+    }
+
+    data := i.MessageComponentData()
+    parts := strings.SplitN(data.CustomID, ":", 3)
+    if len(parts) != 3 {
+        return
+    }
+
+    handler, ok := lc.componentHandlers[parts[0]]
+    if !ok {
+        return
+    }
+
+    log.Info().Printf("trigger %s component interaction", data.CustomID)
+
+    response, isAnswered, isKnown := handler(s, i, parts[1], parts[2])
+    if !isKnown {
+        log.Warn().Printf("%s: unknown component action %s", parts[0], parts[1])
+        return
+    }
+
+    // Answered components are replaced with the result, others keep waiting for the right user
+    interactionResponse := &discordgo.InteractionResponse{
+        Type: discordgo.InteractionResponseChannelMessageWithSource,
+        Data: &discordgo.InteractionResponseData{
+            Embeds: []*discordgo.MessageEmbed{
+                response.ToEmbededMessage(),
+            },
+            Flags: discordgo.MessageFlagsEphemeral,
+        },
+    }
+
+    if isAnswered {
+        interactionResponse = &discordgo.InteractionResponse{
+            Type: discordgo.InteractionResponseUpdateMessage,
+            Data: &discordgo.InteractionResponseData{
+                Content: "",
+                Embeds: []*discordgo.MessageEmbed{
+                    response.ToEmbededMessage(),
+                },
+                Components: []discordgo.MessageComponent{},
+            },
+        }
+    }
+
+    if err := s.InteractionRespond(i.Interaction, interactionResponse); err != nil {
+        log.Error().Printf("%s: interaction response: %v", parts[0], err)
+    }
+}
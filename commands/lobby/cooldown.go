@@ -0,0 +1,275 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandCooldown string = "cooldown" // Subcommand lobby cooldown
+    optionBlock     string = "block"    // Option for commandCooldown
+    optionBurst     string = "burst"    // Option for commandCooldown
+)
+
+var (
+    minCooldown float64 = 0   // Rooms can be created without waiting, the burst limit still applies
+    maxCooldown float64 = 600 // Users wait for 10 minutes at most
+    minBurst    float64 = 0   // Unlimited rooms within burstWindow
+    maxBurst    float64 = 20
+)
+
+const (
+    burstWindow       = 5 * time.Minute  // Window of the burst limit
+    offenderThreshold = 3                // Rejected creations before the user is considered a repeat offender
+    blockDuration     = 10 * time.Minute // Time repeat offenders are blocked for, if the lobby blocks them
+)
+
+// creationLimiter keeps track of rooms created by users to stop them from spamming channels.
+type creationLimiter struct {
+    mu           sync.Mutex
+    creations    map[string][]time.Time // Lobby and user key to creation times within burstWindow
+    violations   map[string]int         // Lobby and user key to rejected creations in a row
+    blockedUntil map[string]time.Time   // Lobby and user key to the end of the block
+}
+
+// init creates the maps on first use, the caller holds the lock.
+func (cl *creationLimiter) init() {
+    if cl.creations == nil {
+        cl.creations = make(map[string][]time.Time)
+        cl.violations = make(map[string]int)
+        cl.blockedUntil = make(map[string]time.Time)
+    }
+}
+
+/* ------ COMMANDS ------ */
+
+func getCooldownCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandCooldown,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select how often a member can create a new channel.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionSeconds,
+                Description: "A time in seconds between two channels of the same member.",
+                MinValue:    &minCooldown,
+                MaxValue:    maxCooldown,
+                Required:    true,
+            },
+            {
+                Type:        discordgo.ApplicationCommandOptionBoolean,
+                Name:        optionBlock,
+                Description: "Whether repeat offenders are temporarily blocked.",
+                Required:    false,
+            },
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionBurst,
+                Description: "Channels a member can create within 5 minutes, 0 for unlimited.",
+                MinValue:    &minBurst,
+                MaxValue:    maxBurst,
+                Required:    false,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandCooldown(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    seconds := options[0].Options[1].IntValue()
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: cooldown command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Cooldown: sql.NullInt32{
+            Valid: true,
+            Int32: int32(seconds),
+        },
+    }
+
+    // Optional options are looked up by name, either of them may be omitted
+    for _, option := range options[0].Options[2:] {
+        switch option.Name {
+        case optionBlock:
+            lobby.BlockSpam = sql.NullBool{Valid: true, Bool: option.BoolValue()}
+        case optionBurst:
+            lobby.BurstLimit = sql.NullInt32{Valid: true, Int32: int32(option.IntValue())}
+        }
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: cooldown command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update cooldown for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: cooldown command: save cooldown %ds for %s[%s]", seconds, channel.Name, lobby.Id)
+    description := fmt.Sprintf("Cooldown %ds successfully set for \"%s\".", seconds, channel.Name)
+    if lobby.BurstLimit.Valid {
+        description += fmt.Sprintf("\nBurst limit: %s per %s.", getLimitName(lobby.BurstLimit), burstWindow)
+    }
+
+    return model.CommandSuccess(description)
+}
+
+/* ------ LIMITS ------ */
+
+// allowCreation checks whether the user may create a room in the lobby, otherwise returns the notice for the user.
+// Rejected attempts count towards blocking repeat offenders, successful creations are recorded by recordCreation.
+func (lc *Command) allowCreation(l model.Lobby, userId string) (string, bool) {
+    key := fmt.Sprintf("%s:%s", l.Id, userId)
+    now := time.Now()
+
+    lc.limiter.mu.Lock()
+    defer lc.limiter.mu.Unlock()
+
+    lc.limiter.init()
+
+    if blockedUntil, ok := lc.limiter.blockedUntil[key]; ok {
+        if now.Before(blockedUntil) {
+            log.Warn().Printf("voice updates: user %s is blocked in lobby %s until %s", userId, l.Id, blockedUntil.Format(time.RFC3339))
+            return getBlockNotice(l, blockedUntil), false
+        }
+
+        delete(lc.limiter.blockedUntil, key)
+    }
+
+    var recent []time.Time
+    for _, createdAt := range lc.limiter.creations[key] {
+        if now.Sub(createdAt) < burstWindow {
+            recent = append(recent, createdAt)
+        }
+    }
+    lc.limiter.creations[key] = recent
+
+    // Creations are recorded in order, the last one ends the cooldown and the first one frees the burst limit
+    var waitUntil time.Time
+    cooldown := getCooldown(l)
+    isOnCooldown := cooldown > 0 && len(recent) > 0 && now.Sub(recent[len(recent)-1]) < cooldown
+    if isOnCooldown {
+        waitUntil = recent[len(recent)-1].Add(cooldown)
+    }
+
+    burstLimit := getBurstLimit(l)
+    isBurstExceeded := burstLimit > 0 && len(recent) >= burstLimit
+    if isBurstExceeded {
+        if burstEnd := recent[len(recent)-burstLimit].Add(burstWindow); burstEnd.After(waitUntil) {
+            waitUntil = burstEnd
+        }
+    }
+
+    if !isOnCooldown && !isBurstExceeded {
+        delete(lc.limiter.violations, key)
+        return "", true
+    }
+
+    lc.limiter.violations[key]++
+    violations := lc.limiter.violations[key]
+    log.Warn().Printf(
+        "voice updates: user %s is creating rooms in lobby %s too often (cooldown %t, burst %t, rejected %d)",
+        userId,
+        l.Id,
+        isOnCooldown,
+        isBurstExceeded,
+        violations,
+    )
+
+    if violations >= offenderThreshold {
+        log.Warn().Printf("voice updates: user %s is a repeat offender in lobby %s", userId, l.Id)
+
+        if l.BlockSpam.Valid && l.BlockSpam.Bool {
+            log.Warn().Printf("voice updates: block user %s in lobby %s for %s", userId, l.Id, blockDuration)
+            lc.limiter.blockedUntil[key] = now.Add(blockDuration)
+            delete(lc.limiter.violations, key)
+            return getBlockNotice(l, now.Add(blockDuration)), false
+        }
+    }
+
+    return fmt.Sprintf(
+        "You are creating channels in <#%s> too often, try again in %s.",
+        l.Id,
+        time.Until(waitUntil).Round(time.Second),
+    ), false
+}
+
+// recordCreation counts the room created by the user towards the cooldown and the burst limit of the lobby.
+func (lc *Command) recordCreation(l model.Lobby, userId string) {
+    key := fmt.Sprintf("%s:%s", l.Id, userId)
+
+    lc.limiter.mu.Lock()
+    defer lc.limiter.mu.Unlock()
+
+    lc.limiter.init()
+
+    lc.limiter.creations[key] = append(lc.limiter.creations[key], time.Now())
+}
+
+// moveToExistingRoom moves the user rejected by the limits back into the room they own in the lobby,
+// returns false if they have no room to move to.
+func (lc *Command) moveToExistingRoom(s *discordgo.Session, guildId string, l model.Lobby, userId string) bool {
+    channels, err := lc.channelRepository.GetChannels(guildId)
+    if err != nil {
+        log.Error().Printf("voice updates: get channels: %v", err)
+        return false
+    }
+
+    for _, channel := range channels {
+        if channel.ParentID != l.Id || channel.OwnerID != userId {
+            continue
+        }
+
+        log.Info().Printf("voice updates: move user %s back to the channel %s", userId, channel.Id)
+        if err := s.GuildMemberMove(guildId, userId, &channel.Id); err != nil {
+            log.Error().Printf("voice updates: unable to move user %s to the channel %s: %v", userId, channel.Id, err)
+            return false
+        }
+        return true
+    }
+
+    log.Info().Printf("voice updates: user %s has no room in lobby %s to move back to", userId, l.Id)
+    return false
+}
+
+func getBlockNotice(l model.Lobby, blockedUntil time.Time) string {
+    return fmt.Sprintf(
+        "You are blocked from creating channels in <#%s> for %s.",
+        l.Id,
+        time.Until(blockedUntil).Round(time.Second),
+    )
+}
+
+// getCooldown returns the time between two rooms of the same user, zero if the lobby has no cooldown.
+func getCooldown(l model.Lobby) time.Duration {
+    if !l.Cooldown.Valid || l.Cooldown.Int32 <= 0 {
+        return 0
+    }
+
+    return time.Duration(l.Cooldown.Int32) * time.Second
+}
+
+// getBurstLimit returns the rooms a user can create within burstWindow, zero if the lobby has no burst limit.
+func getBurstLimit(l model.Lobby) int {
+    if !l.BurstLimit.Valid || l.BurstLimit.Int32 <= 0 {
+        return 0
+    }
+
+    return int(l.BurstLimit.Int32)
+}
@@ -0,0 +1,79 @@
+package lobby
+
+import (
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/repository"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// rollback collects actions undoing Discord changes of a unit of work that could not be completed.
+type rollback struct {
+    actions []func()
+}
+
+func (r *rollback) add(action func()) {
+    r.actions = append(r.actions, action)
+}
+
+// run undoes the changes in reverse order.
+func (r *rollback) run() {
+    for index := len(r.actions) - 1; index >= 0; index-- {
+        r.actions[index]()
+    }
+}
+
+// undoChannel returns an action deleting the channel created by an unfinished unit of work.
+func undoChannel(s *discordgo.Session, channelId string) func() {
+    return func() {
+        log.Warn().Printf("voice updates: roll back, delete channel %s", channelId)
+        if _, err := s.ChannelDelete(channelId); err != nil && !isUnknownChannel(err) {
+            log.Error().Printf("voice updates: API: unable to delete channel %s on roll back: %v", channelId, err)
+        }
+    }
+}
+
+// saveRoom stores the new room together with its permits in one transaction.
+func (lc *Command) saveRoom(channel model.Channel, permits []model.ChannelPermit) error {
+    return lc.transactor.InTx(func(tx repository.Tx) error {
+        if err := tx.Channels().SetChannel(&channel); err != nil {
+            return fmt.Errorf("db: %w", err)
+        }
+
+        for _, permit := range permits {
+            if err := tx.ChannelPermits().SetChannelPermit(&permit); err != nil {
+                return fmt.Errorf("db: %w", err)
+            }
+        }
+
+        return nil
+    })
+}
+
+// discardRoom removes the stored room, its members and permits in one transaction.
+func (lc *Command) discardRoom(guildId string, channel model.Channel) error {
+    lc.cancelRename(channel.Id)
+
+    return lc.transactor.InTx(func(tx repository.Tx) error {
+        return deleteRoomRows(tx, guildId, channel.Id)
+    })
+}
+
+// deleteRoomRows removes the room with its members and permits within the transaction.
+func deleteRoomRows(tx repository.Tx, guildId string, channelId string) error {
+    if err := tx.Channels().DeleteChannel(channelId); err != nil {
+        return fmt.Errorf("db: unable to delete channel %s: %w", channelId, err)
+    }
+
+    if err := tx.ChannelMembers().DeleteChannelMembers(guildId, channelId); err != nil {
+        return fmt.Errorf("db: unable to delete channel members %s: %w", channelId, err)
+    }
+
+    if err := tx.ChannelPermits().DeleteChannelPermits(channelId); err != nil {
+        return fmt.Errorf("db: unable to delete channel permits %s: %w", channelId, err)
+    }
+
+    return nil
+}
@@ -0,0 +1,118 @@
+package lobby
+
+import (
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/repository"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// deleteEmptyChannel deletes the channel right away or after the grace period of its lobby.
+func (lc *Command) deleteEmptyChannel(s *discordgo.Session, guildId string, channel model.Channel) {
+    if lc.deletions.Has(channel.Id) {
+        return
+    }
+
+    // Deletion was scheduled before the bot restarted
+    if channel.DeleteAt > 0 {
+        lc.scheduleDeletion(s, guildId, channel.Id, time.Until(time.Unix(channel.DeleteAt, 0)))
+        return
+    }
+
+    gracePeriod := lc.getGracePeriod(guildId, channel.ParentID)
+    if gracePeriod == 0 {
+        log.Info().Printf("voice updates: channel %s is empty, deleting..", channel.Id)
+        if err := lc.deleteChannel(s, guildId, channel); err != nil {
+            log.Error().Printf("voice updates: %v", err)
+        }
+        return
+    }
+
+    deleteAt := time.Now().Add(gracePeriod)
+    if err := lc.channelRepository.SetChannelDeleteAt(channel.Id, deleteAt.Unix()); err != nil {
+        log.Error().Printf("voice updates: db: unable to schedule channel %s deletion: %v", channel.Id, err)
+    }
+
+    lc.scheduleDeletion(s, guildId, channel.Id, gracePeriod)
+}
+
+// scheduleDeletion deletes the channel after delay, unless someone joins it in the meantime.
+func (lc *Command) scheduleDeletion(s *discordgo.Session, guildId string, channelId string, delay time.Duration) {
+    log.Info().Printf("voice updates: channel %s is empty, deleting in %s..", channelId, delay)
+
+    lc.deletions.Schedule(channelId, delay, func() {
+        channelMembersCount, err := lc.channelMembersRepository.GetChannelMembersCount(guildId, channelId)
+        if err != nil {
+            log.Error().Printf("voice updates: get channel members count: %v", err)
+            return
+        }
+
+        if channelMembersCount > 0 {
+            log.Info().Printf("voice updates: channel %s is not empty anymore, keep it", channelId)
+            if err := lc.channelRepository.SetChannelDeleteAt(channelId, 0); err != nil {
+                log.Error().Printf("voice updates: db: unable to cancel channel %s deletion: %v", channelId, err)
+            }
+            return
+        }
+
+        channel, err := lc.channelRepository.GetChannel(channelId, guildId)
+        if err != nil {
+            log.Error().Printf("voice updates: get channel: %v", err)
+            return
+        }
+
+        log.Info().Printf("voice updates: grace period of channel %s is over, deleting..", channelId)
+        if err := lc.deleteChannel(s, guildId, channel); err != nil {
+            log.Error().Printf("voice updates: %v", err)
+        }
+    })
+}
+
+// cancelDeletion keeps the channel alive when someone joins it during the grace period.
+func (lc *Command) cancelDeletion(channel model.Channel) {
+    if !lc.deletions.Cancel(channel.Id) && channel.DeleteAt == 0 {
+        return
+    }
+
+    log.Info().Printf("voice updates: someone joined channel %s, cancel deletion", channel.Id)
+    if err := lc.channelRepository.SetChannelDeleteAt(channel.Id, 0); err != nil {
+        log.Error().Printf("voice updates: db: unable to cancel channel %s deletion: %v", channel.Id, err)
+    }
+}
+
+// deleteChannel deletes the room in Discord first and commits the removal of its rows afterwards, so no transaction is
+// held open during API calls. Rows are kept if Discord refuses to delete a channel, the next attempt deletes what is
+// left since already deleted channels count as deleted. If the commit fails instead, the rows of the deleted room are
+// removed by the next reconcile.
+func (lc *Command) deleteChannel(s *discordgo.Session, guildId string, channel model.Channel) error {
+    lc.cancelRename(channel.Id)
+
+    if err := lc.removeTextChannel(s, channel); err != nil {
+        return err
+    }
+
+    if _, err := s.ChannelDelete(channel.Id); err != nil && !isUnknownChannel(err) {
+        return fmt.Errorf("API: unable to delete channel %s: %w", channel.Id, err)
+    }
+
+    return lc.transactor.InTx(func(tx repository.Tx) error {
+        return deleteRoomRows(tx, guildId, channel.Id)
+    })
+}
+
+func (lc *Command) getGracePeriod(guildId string, lobbyId string) time.Duration {
+    lobby, err := lc.lobbyRepository.GetLobby(lobbyId, guildId)
+    if err != nil {
+        log.Warn().Printf("voice updates: get lobby: %v", err)
+        return 0
+    }
+
+    if !lobby.Grace.Valid || lobby.Grace.Int32 <= 0 {
+        return 0
+    }
+
+    return time.Duration(lobby.Grace.Int32) * time.Second
+}
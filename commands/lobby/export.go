@@ -0,0 +1,232 @@
+package lobby
+
+import (
+    "bytes"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+
+    "github.com/bwmarrin/discordgo"
+    "gopkg.in/yaml.v3"
+)
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandExport(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    format := formatJSON
+    if options := i.ApplicationCommandData().Options[0].Options; len(options) > 0 {
+        format = options[0].StringValue()
+    }
+
+    response := model.CommandSuccess("Lobbies successfully exported.")
+    var files []*discordgo.File
+
+    document, err := lc.exportLobbies(s, i.GuildID)
+    if err != nil {
+        log.Error().Printf("lobby: export command: %v", err)
+        response = model.CommandError("Unable to export lobbies!")
+    }
+
+    if err == nil {
+        var data []byte
+        if format == formatYAML {
+            data, err = yaml.Marshal(document)
+        } else {
+            data, err = json.MarshalIndent(document, "", "  ")
+        }
+
+        if err != nil {
+            log.Error().Printf("lobby: export command: unable to encode lobbies of guild %s: %v", i.GuildID, err)
+            response = model.CommandError("Unable to export lobbies!")
+        } else {
+            log.Info().Printf("lobby: export command: export %d lobbies of guild %s", len(document.Lobbies), i.GuildID)
+            response = model.CommandSuccess(fmt.Sprintf("%d lobbies successfully exported.", len(document.Lobbies)))
+            files = []*discordgo.File{
+                {
+                    Name:        fmt.Sprintf("lobbies.%s", format),
+                    ContentType: fmt.Sprintf("application/%s", format),
+                    Reader:      bytes.NewReader(data),
+                },
+            }
+        }
+    }
+
+    if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+        Type: discordgo.InteractionResponseChannelMessageWithSource,
+        Data: &discordgo.InteractionResponseData{
+            Embeds: []*discordgo.MessageEmbed{
+                response.ToEmbededMessage(),
+            },
+            Files: files,
+            Flags: discordgo.MessageFlagsEphemeral,
+        },
+    }); err != nil {
+        log.Error().Printf("lobby: interaction response: %v", err)
+    }
+}
+
+/* ------ EXPORT ------ */
+
+func (lc *Command) exportLobbies(s *discordgo.Session, guildId string) (lobbyExport, error) {
+    document := lobbyExport{Version: exportVersion, Lobbies: []lobbyConfig{}}
+
+    guild, err := lc.guildRepository.GetGuild(guildId)
+    if err != nil && !errors.Is(err, sql.ErrNoRows) {
+        return document, fmt.Errorf("db: %w", err)
+    }
+    document.MaxRooms = exportInt32(guild.MaxRooms)
+
+    lobbies, err := lc.lobbyRepository.GetLobbies(guildId)
+    if err != nil {
+        return document, fmt.Errorf("db: %w", err)
+    }
+
+    for _, l := range lobbies {
+        config := lobbyConfig{
+            ID:           l.Id,
+            Name:         getChannelName(s, l.Id),
+            Template:     exportString(l.Template),
+            Capacity:     exportInt32(l.Capacity),
+            Naming:       exportEnum(l.Naming, namingNames),
+            Grace:        exportInt32(l.Grace),
+            Region:       exportString(l.RTCRegion),
+            Video:        exportEnum(l.VideoQuality, videoNames),
+            NSFW:         exportBool(l.NSFW),
+            Text:         exportEnum(l.TextChannel, textNames),
+            Activity:     exportBool(l.Activity),
+            Overrides:    exportBool(l.Overrides),
+            Cooldown:     exportInt32(l.Cooldown),
+            BurstLimit:   exportInt32(l.BurstLimit),
+            BlockSpam:    exportBool(l.BlockSpam),
+            MaxRooms:     exportInt32(l.MaxRooms),
+            Permissions:  exportEnum(l.Permissions, permissionNames),
+            Placement:    exportEnum(l.Placement, placementNames),
+            AutoOverflow: exportBool(l.AutoOverflow),
+        }
+
+        if l.Bitrate.Valid && int(l.Bitrate.Int32) >= discord.MinBitrate {
+            bitrate := l.Bitrate.Int32 / 1000
+            config.Bitrate = &bitrate
+        }
+
+        if config.Region != nil && *config.Region == "" {
+            region := regionAutomatic
+            config.Region = &region
+        }
+
+        if l.WaitingID.Valid && l.WaitingID.String != "" {
+            config.Waiting = &reference{ID: l.WaitingID.String, Name: getChannelName(s, l.WaitingID.String)}
+        }
+
+        roles, err := lc.lobbyRolesRepository.GetLobbyRoles(l.Id)
+        if err != nil {
+            return document, fmt.Errorf("db: %w", err)
+        }
+
+        for _, role := range roles {
+            config.Roles = append(config.Roles, roleConfig{
+                ID:      role.RoleID,
+                Name:    getRoleName(s, guildId, role.RoleID),
+                Allowed: role.Allowed,
+            })
+        }
+
+        overwrites, err := lc.lobbyOverwritesRepository.GetLobbyOverwrites(l.Id)
+        if err != nil {
+            return document, fmt.Errorf("db: %w", err)
+        }
+
+        for _, overwrite := range overwrites {
+            exported := overwriteConfig{
+                ID:    overwrite.TargetID,
+                Type:  "member",
+                Allow: overwrite.Allow,
+                Deny:  overwrite.Deny,
+            }
+
+            if overwrite.Type == discordgo.PermissionOverwriteTypeRole {
+                exported.Name = getRoleName(s, guildId, overwrite.TargetID)
+                exported.Type = "role"
+            }
+
+            config.Overwrites = append(config.Overwrites, exported)
+        }
+
+        overflows, err := lc.lobbyOverflowsRepository.GetLobbyOverflows(l.Id)
+        if err != nil {
+            return document, fmt.Errorf("db: %w", err)
+        }
+
+        for _, overflow := range overflows {
+            config.Overflows = append(config.Overflows, reference{
+                ID:   overflow.CategoryID,
+                Name: getChannelName(s, overflow.CategoryID),
+            })
+        }
+
+        document.Lobbies = append(document.Lobbies, config)
+    }
+
+    return document, nil
+}
+
+func exportString(value sql.NullString) *string {
+    if !value.Valid {
+        return nil
+    }
+
+    return &value.String
+}
+
+func exportInt32(value sql.NullInt32) *int32 {
+    if !value.Valid {
+        return nil
+    }
+
+    return &value.Int32
+}
+
+func exportBool(value sql.NullBool) *bool {
+    if !value.Valid {
+        return nil
+    }
+
+    return &value.Bool
+}
+
+// exportEnum returns the name of the enum value, unknown values are left out.
+func exportEnum(value sql.NullInt32, names map[string]int32) *string {
+    if !value.Valid {
+        return nil
+    }
+
+    for name, number := range names {
+        if number == value.Int32 {
+            return &name
+        }
+    }
+
+    return nil
+}
+
+func getChannelName(s *discordgo.Session, channelId string) string {
+    channel, err := s.State.Channel(channelId)
+    if err != nil {
+        return ""
+    }
+
+    return channel.Name
+}
+
+func getRoleName(s *discordgo.Session, guildId string, roleId string) string {
+    role, err := s.State.Role(guildId, roleId)
+    if err != nil {
+        return ""
+    }
+
+    return role.Name
+}
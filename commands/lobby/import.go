@@ -0,0 +1,251 @@
+package lobby
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "io"
+    "net/http"
+    "path"
+    "strings"
+    "sync"
+
+    "github.com/bwmarrin/discordgo"
+    "gopkg.in/yaml.v3"
+)
+
+// lobbyImport is a validated import waiting for the confirmation of the caller.
+type lobbyImport struct {
+    guildId string
+    userId  string
+    guild   *model.Guild // Nil if the guild room limit is unchanged
+    lobbies []lobbyPlan  // Changed lobbies only
+}
+
+type lobbyPlan struct {
+    lobby      model.Lobby // Every setting is set, so the stored lobby is replaced
+    isNew      bool
+    roles      []model.LobbyRole
+    overwrites []model.LobbyOverwrite
+    overflows  []string // Categories in order of use
+}
+
+// imports keeps pending imports until the caller answers them or they time out.
+type imports struct {
+    mu      sync.Mutex
+    pending map[string]lobbyImport // Interaction id to the import
+}
+
+/* ------ INTERACTIONS ------ */
+
+// handleCommandImport answers with the preview of the changes and the buttons to confirm or cancel them.
+// The answer is deferred, as the attachment is downloaded first.
+func (lc *Command) handleCommandImport(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+        Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+        Data: &discordgo.InteractionResponseData{
+            Flags: discordgo.MessageFlagsEphemeral,
+        },
+    }); err != nil {
+        log.Error().Printf("lobby: interaction response: %v", err)
+        return
+    }
+
+    response, components := lc.prepareImport(s, i)
+
+    edit := &discordgo.WebhookEdit{
+        Embeds: &[]*discordgo.MessageEmbed{
+            response.ToEmbededMessage(),
+        },
+    }
+    if components != nil {
+        edit.Components = &components
+    }
+
+    if _, err := s.InteractionResponseEdit(i.Interaction, edit); err != nil {
+        log.Error().Printf("lobby: interaction response: %v", err)
+    }
+}
+
+func (lc *Command) prepareImport(s *discordgo.Session, i *discordgo.InteractionCreate) (model.CommandResponse, []discordgo.MessageComponent) {
+    data := i.ApplicationCommandData()
+    attachmentId, _ := data.Options[0].Options[0].Value.(string)
+
+    attachment, ok := data.Resolved.Attachments[attachmentId]
+    if !ok {
+        log.Warn().Printf("lobby: import command: attachment %s is not resolved", attachmentId)
+        return model.CommandError("Unable to read the file!"), nil
+    }
+
+    if attachment.Size > maxImportSize {
+        log.Warn().Printf("lobby: import command: attachment %s is %d bytes", attachment.Filename, attachment.Size)
+        return model.CommandWarning(fmt.Sprintf("The file is too large, %d KB at most!", maxImportSize/1024)), nil
+    }
+
+    document, err := downloadExport(s.Client, attachment)
+    if err != nil {
+        log.Warn().Printf("lobby: import command: %v", err)
+        return model.CommandWarning(fmt.Sprintf("Unable to read the file!\n%v", err)), nil
+    }
+
+    imp, issues := lc.planImport(s, i.GuildID, document)
+    if len(issues) > 0 {
+        log.Warn().Printf("lobby: import command: %d issues in %s: %s", len(issues), attachment.Filename, strings.Join(issues, "; "))
+        if len(issues) > maxIssues {
+            issues = append(issues[:maxIssues], fmt.Sprintf("and %d more..", len(issues)-maxIssues))
+        }
+
+        return model.CommandWarning(fmt.Sprintf("The file cannot be imported:\n- %s", strings.Join(issues, "\n- "))), nil
+    }
+
+    preview, err := lc.getImportPreview(&imp)
+    if err != nil {
+        log.Error().Printf("lobby: import command: %v", err)
+        return model.CommandError("Unable to compare the file with the current lobbies!"), nil
+    }
+
+    if preview == "" {
+        log.Info().Printf("lobby: import command: %s matches lobbies of guild %s", attachment.Filename, i.GuildID)
+        return model.CommandWarning("Lobbies already have these settings, nothing to import."), nil
+    }
+
+    imp.userId = i.Member.User.ID
+    key := i.ID
+
+    lc.imports.mu.Lock()
+    if lc.imports.pending == nil {
+        lc.imports.pending = make(map[string]lobbyImport)
+    }
+    lc.imports.pending[key] = imp
+    lc.imports.mu.Unlock()
+
+    lc.importTimeouts.Schedule(key, importTimeout, func() {
+        lc.removeImport(key)
+    })
+
+    log.Info().Printf("lobby: import command: %d lobbies of guild %s wait for confirmation", len(imp.lobbies), i.GuildID)
+    return model.CommandSuccess(fmt.Sprintf("Review the changes, lobbies missing from the file are kept:\n%s", preview)),
+        []discordgo.MessageComponent{
+            discordgo.ActionsRow{
+                Components: []discordgo.MessageComponent{
+                    discordgo.Button{
+                        Label:    "Confirm",
+                        Style:    discordgo.SuccessButton,
+                        CustomID: fmt.Sprintf("%s:%s:%s", importPrefix, importConfirm, key),
+                    },
+                    discordgo.Button{
+                        Label:    "Cancel",
+                        Style:    discordgo.SecondaryButton,
+                        CustomID: fmt.Sprintf("%s:%s:%s", importPrefix, importCancel, key),
+                    },
+                },
+            },
+        }
+}
+
+// handleImportComponent handles the confirmation of imports.
+func (lc *Command) handleImportComponent(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    action string,
+    key string,
+) (model.CommandResponse, bool, bool) {
+    var response model.CommandResponse
+    var isAnswered bool
+    switch action {
+    case importConfirm:
+        response, isAnswered = lc.handleImportAnswer(i, key, true)
+    case importCancel:
+        response, isAnswered = lc.handleImportAnswer(i, key, false)
+    default:
+        return response, false, false
+    }
+
+    return response, isAnswered, true
+}
+
+func (lc *Command) handleImportAnswer(
+    i *discordgo.InteractionCreate,
+    key string,
+    confirmed bool,
+) (model.CommandResponse, bool) {
+    lc.imports.mu.Lock()
+    imp, ok := lc.imports.pending[key]
+    lc.imports.mu.Unlock()
+
+    if !ok {
+        return model.CommandWarning("The import has expired, run /lobby import again."), true
+    }
+
+    if i.Member.User.ID != imp.userId {
+        return model.CommandWarning("Only the member who started the import can answer it!"), false
+    }
+
+    lc.removeImport(key)
+
+    if !confirmed {
+        log.Info().Printf("lobby: import: %s cancelled the import for guild %s", imp.userId, imp.guildId)
+        return model.CommandSuccess("Import cancelled, nothing was changed."), true
+    }
+
+    if err := lc.applyImport(imp); err != nil {
+        log.Error().Printf("lobby: import: unable to import lobbies of guild %s: %v", imp.guildId, err)
+        return model.CommandError("Unable to import lobbies, nothing was changed."), true
+    }
+
+    log.Info().Printf("lobby: import: %s imported %d lobbies for guild %s", imp.userId, len(imp.lobbies), imp.guildId)
+    return model.CommandSuccess(fmt.Sprintf("%d lobbies successfully imported.", len(imp.lobbies))), true
+}
+
+func (lc *Command) removeImport(key string) {
+    lc.importTimeouts.Cancel(key)
+
+    lc.imports.mu.Lock()
+    delete(lc.imports.pending, key)
+    lc.imports.mu.Unlock()
+}
+
+/* ------ IMPORT ------ */
+
+// downloadExport reads the exported document from the attachment, YAML files are told apart by their extension.
+func downloadExport(client *http.Client, attachment *discordgo.MessageAttachment) (lobbyExport, error) {
+    var document lobbyExport
+
+    response, err := client.Get(attachment.URL)
+    if err != nil {
+        return document, fmt.Errorf("unable to download %s: %w", attachment.Filename, err)
+    }
+    defer response.Body.Close()
+
+    if response.StatusCode != http.StatusOK {
+        return document, fmt.Errorf("unable to download %s: %s", attachment.Filename, response.Status)
+    }
+
+    data, err := io.ReadAll(io.LimitReader(response.Body, maxImportSize))
+    if err != nil {
+        return document, fmt.Errorf("unable to download %s: %w", attachment.Filename, err)
+    }
+
+    switch strings.ToLower(path.Ext(attachment.Filename)) {
+    case ".yaml", ".yml":
+        decoder := yaml.NewDecoder(bytes.NewReader(data))
+        decoder.KnownFields(true)
+        err = decoder.Decode(&document)
+    default:
+        decoder := json.NewDecoder(bytes.NewReader(data))
+        decoder.DisallowUnknownFields()
+        err = decoder.Decode(&document)
+    }
+
+    if err != nil {
+        return document, fmt.Errorf("%s is not a lobby export: %w", attachment.Filename, err)
+    }
+
+    if document.Version != exportVersion {
+        return document, fmt.Errorf("version %d of %s is not supported, expected %d", document.Version, attachment.Filename, exportVersion)
+    }
+
+    return document, nil
+}
@@ -0,0 +1,58 @@
+package lobby
+
+import "hometown-bot/repository"
+
+// applyImport stores all changes of the import in a single unit of work.
+func (lc *Command) applyImport(imp lobbyImport) error {
+    return lc.transactor.InTx(func(tx repository.Tx) error {
+        if imp.guild != nil {
+            if err := tx.Guilds().UpsertGuild(imp.guild); err != nil {
+                return err
+            }
+        }
+
+        for _, plan := range imp.lobbies {
+            if plan.isNew {
+                if _, err := tx.Lobbies().SetLobby(&plan.lobby); err != nil {
+                    return err
+                }
+            }
+
+            if err := tx.Lobbies().UpsertLobby(&plan.lobby); err != nil {
+                return err
+            }
+
+            if err := tx.LobbyRoles().DeleteLobbyRoles(plan.lobby.Id); err != nil {
+                return err
+            }
+
+            for _, role := range plan.roles {
+                if err := tx.LobbyRoles().SetLobbyRole(&role); err != nil {
+                    return err
+                }
+            }
+
+            if err := tx.LobbyOverwrites().DeleteLobbyOverwrites(plan.lobby.Id); err != nil {
+                return err
+            }
+
+            for _, overwrite := range plan.overwrites {
+                if err := tx.LobbyOverwrites().SetLobbyOverwrite(&overwrite); err != nil {
+                    return err
+                }
+            }
+
+            if err := tx.LobbyOverflows().DeleteLobbyOverflows(plan.lobby.Id); err != nil {
+                return err
+            }
+
+            for _, categoryId := range plan.overflows {
+                if _, err := tx.LobbyOverflows().AddLobbyOverflow(plan.lobby.Id, categoryId); err != nil {
+                    return err
+                }
+            }
+        }
+
+        return nil
+    })
+}
@@ -0,0 +1,308 @@
+package lobby
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+    "hometown-bot/util/placeholder"
+    "sort"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// planImport validates the document against the guild and resolves its channels and roles.
+// Lobby channels are matched by id, or by name when the document comes from another guild.
+func (lc *Command) planImport(s *discordgo.Session, guildId string, document lobbyExport) (lobbyImport, []string) {
+    imp := lobbyImport{guildId: guildId}
+
+    guild, err := s.State.Guild(guildId)
+    if err != nil {
+        return imp, []string{fmt.Sprintf("unable to get the server: %v", err)}
+    }
+
+    var issues []string
+    if err := checkRange("server max_rooms", document.MaxRooms, minRooms, maxGuildRooms); err != nil {
+        issues = append(issues, err.Error())
+    }
+
+    current, err := lc.guildRepository.GetGuild(guildId)
+    if err != nil && !errors.Is(err, sql.ErrNoRows) {
+        return imp, []string{fmt.Sprintf("unable to get the server settings: %v", err)}
+    }
+
+    if maxRooms := valueOr(document.MaxRooms, 0); maxRooms != current.MaxRooms.Int32 {
+        imp.guild = &model.Guild{Id: guildId, MaxRooms: sql.NullInt32{Valid: true, Int32: maxRooms}}
+    }
+
+    tier := getPremiumTier(s, guildId)
+    imported := make(map[string]string, len(document.Lobbies))
+    for index, config := range document.Lobbies {
+        lobbyName := fmt.Sprintf("lobby #%d \"%s\"", index+1, config.Name)
+
+        channel, err := resolveChannel(guild.Channels, reference{ID: config.ID, Name: config.Name}, discordgo.ChannelTypeGuildVoice)
+        if err != nil {
+            issues = append(issues, fmt.Sprintf("%s: %v", lobbyName, err))
+            continue
+        }
+
+        if previous, ok := imported[channel.ID]; ok {
+            issues = append(issues, fmt.Sprintf("%s: <#%s> is already imported as %s", lobbyName, channel.ID, previous))
+            continue
+        }
+        imported[channel.ID] = lobbyName
+
+        plan, lobbyIssues := buildLobbyPlan(s, guild, tier, channel, config)
+        for _, issue := range lobbyIssues {
+            issues = append(issues, fmt.Sprintf("%s: %s", lobbyName, issue))
+        }
+
+        if len(lobbyIssues) > 0 {
+            continue
+        }
+
+        if _, err := lc.lobbyRepository.GetLobby(channel.ID, guildId); errors.Is(err, sql.ErrNoRows) {
+            plan.isNew = true
+        } else if err != nil {
+            return imp, []string{fmt.Sprintf("unable to get lobby <#%s>: %v", channel.ID, err)}
+        }
+
+        imp.lobbies = append(imp.lobbies, plan)
+    }
+
+    return imp, issues
+}
+
+// buildLobbyPlan converts the lobby settings of the document, settings missing from it get their default values.
+func buildLobbyPlan(
+    s *discordgo.Session,
+    guild *discordgo.Guild,
+    tier discordgo.PremiumTier,
+    channel *discordgo.Channel,
+    config lobbyConfig,
+) (lobbyPlan, []string) {
+    var issues []string
+    addIssue := func(err error) {
+        if err != nil {
+            issues = append(issues, err.Error())
+        }
+    }
+
+    template := valueOr(config.Template, "")
+    if err := placeholder.Validate(template); err != nil {
+        addIssue(fmt.Errorf("template: %w", err))
+    }
+
+    addIssue(checkRange("capacity", config.Capacity, 0, maxCapacity))
+    addIssue(checkRange("grace", config.Grace, minGracePeriod, maxGracePeriod))
+    addIssue(checkRange("cooldown", config.Cooldown, minCooldown, maxCooldown))
+    addIssue(checkRange("burst_limit", config.BurstLimit, minBurst, maxBurst))
+    addIssue(checkRange("max_rooms", config.MaxRooms, minRooms, maxLobbyRooms))
+
+    bitrate := valueOr(config.Bitrate, 0) * 1000
+    if maxBitrate := discord.MaxBitrate(tier); config.Bitrate != nil && (bitrate < int32(discord.MinBitrate) || bitrate > int32(maxBitrate)) {
+        addIssue(fmt.Errorf("bitrate %d kbps is not within %d-%d kbps allowed by server boost level %d",
+            bitrate/1000, discord.MinBitrate/1000, maxBitrate/1000, tier))
+    }
+
+    region := strings.ToLower(strings.TrimSpace(valueOr(config.Region, "")))
+    if region == regionAutomatic {
+        region = ""
+    }
+    if region != "" {
+        if _, err := validateRegion(s, region); err != nil {
+            addIssue(fmt.Errorf("region: %w", err))
+        }
+    }
+
+    naming, err := importEnum("naming", config.Naming, namingNames, int32(model.NamingTemplate))
+    addIssue(err)
+    video, err := importEnum("video", config.Video, videoNames, 0)
+    addIssue(err)
+    text, err := importEnum("text", config.Text, textNames, int32(model.TextChannelNone))
+    addIssue(err)
+    permissions, err := importEnum("permissions", config.Permissions, permissionNames, int32(model.PermissionSourceCategory))
+    addIssue(err)
+    placement, err := importEnum("placement", config.Placement, placementNames, int32(model.PlacementBelow))
+    addIssue(err)
+
+    var waitingId string
+    if config.Waiting != nil {
+        waiting, err := resolveChannel(guild.Channels, *config.Waiting, discordgo.ChannelTypeGuildVoice)
+        if err != nil {
+            addIssue(fmt.Errorf("waiting: %w", err))
+        } else if waiting.ID == channel.ID {
+            addIssue(errors.New("waiting: the lobby cannot be its own waiting room"))
+        } else {
+            waitingId = waiting.ID
+        }
+    }
+
+    plan := lobbyPlan{
+        lobby: model.Lobby{
+            Id:           channel.ID,
+            CategoryID:   channel.ParentID,
+            GuildID:      guild.ID,
+            Template:     sql.NullString{Valid: true, String: template},
+            Capacity:     sql.NullInt32{Valid: true, Int32: valueOr(config.Capacity, 0)},
+            Naming:       sql.NullInt32{Valid: true, Int32: naming},
+            Grace:        sql.NullInt32{Valid: true, Int32: valueOr(config.Grace, 0)},
+            Bitrate:      sql.NullInt32{Valid: true, Int32: bitrate},
+            RTCRegion:    sql.NullString{Valid: true, String: region},
+            VideoQuality: sql.NullInt32{Valid: true, Int32: video},
+            NSFW:         sql.NullBool{Valid: true, Bool: valueOr(config.NSFW, false)},
+            TextChannel:  sql.NullInt32{Valid: true, Int32: text},
+            Activity:     sql.NullBool{Valid: true, Bool: valueOr(config.Activity, false)},
+            Overrides:    sql.NullBool{Valid: true, Bool: valueOr(config.Overrides, false)},
+            Cooldown:     sql.NullInt32{Valid: true, Int32: valueOr(config.Cooldown, 0)},
+            BurstLimit:   sql.NullInt32{Valid: true, Int32: valueOr(config.BurstLimit, 0)},
+            BlockSpam:    sql.NullBool{Valid: true, Bool: valueOr(config.BlockSpam, false)},
+            MaxRooms:     sql.NullInt32{Valid: true, Int32: valueOr(config.MaxRooms, 0)},
+            WaitingID:    sql.NullString{Valid: true, String: waitingId},
+            Permissions:  sql.NullInt32{Valid: true, Int32: permissions},
+            Placement:    sql.NullInt32{Valid: true, Int32: placement},
+            AutoOverflow: sql.NullBool{Valid: true, Bool: valueOr(config.AutoOverflow, false)},
+        },
+    }
+
+    for _, config := range config.Roles {
+        role, err := resolveRole(guild.Roles, reference{ID: config.ID, Name: config.Name})
+        if err != nil {
+            addIssue(fmt.Errorf("roles: %w", err))
+            continue
+        }
+
+        plan.roles = append(plan.roles, model.LobbyRole{LobbyID: channel.ID, RoleID: role.ID, Allowed: config.Allowed})
+    }
+
+    for _, config := range config.Overwrites {
+        overwriteType, err := importEnum("overwrites type", &config.Type, overwriteNames, 0)
+        if err != nil {
+            addIssue(err)
+            continue
+        }
+
+        targetId := config.ID
+        if discordgo.PermissionOverwriteType(overwriteType) == discordgo.PermissionOverwriteTypeRole {
+            role, err := resolveRole(guild.Roles, reference{ID: config.ID, Name: config.Name})
+            if err != nil {
+                addIssue(fmt.Errorf("overwrites: %w", err))
+                continue
+            }
+
+            targetId = role.ID
+        }
+
+        if config.Allow&config.Deny != 0 {
+            addIssue(fmt.Errorf("overwrites: permissions %d of %s are both allowed and denied", config.Allow&config.Deny, targetId))
+            continue
+        }
+
+        plan.overwrites = append(plan.overwrites, model.LobbyOverwrite{
+            LobbyID:  channel.ID,
+            TargetID: targetId,
+            Type:     discordgo.PermissionOverwriteType(overwriteType),
+            Allow:    config.Allow,
+            Deny:     config.Deny,
+        })
+    }
+
+    for _, overflow := range config.Overflows {
+        category, err := resolveChannel(guild.Channels, overflow, discordgo.ChannelTypeGuildCategory)
+        if err != nil {
+            addIssue(fmt.Errorf("overflows: %w", err))
+            continue
+        }
+
+        plan.overflows = append(plan.overflows, category.ID)
+    }
+
+    return plan, issues
+}
+
+// resolveChannel finds the channel by its id, or by its name if the id is not in the guild.
+func resolveChannel(channels []*discordgo.Channel, ref reference, channelType discordgo.ChannelType) (*discordgo.Channel, error) {
+    var named []*discordgo.Channel
+    for _, channel := range channels {
+        if channel.Type != channelType {
+            continue
+        }
+
+        if channel.ID == ref.ID {
+            return channel, nil
+        }
+
+        if ref.Name != "" && channel.Name == ref.Name {
+            named = append(named, channel)
+        }
+    }
+
+    switch len(named) {
+    case 0:
+        return nil, fmt.Errorf("channel \"%s\" [%s] is not found", ref.Name, ref.ID)
+    case 1:
+        return named[0], nil
+    default:
+        return nil, fmt.Errorf("%d channels are named \"%s\", use the channel id", len(named), ref.Name)
+    }
+}
+
+// resolveRole finds the role by its id, or by its name if the id is not in the guild.
+func resolveRole(roles []*discordgo.Role, ref reference) (*discordgo.Role, error) {
+    var named []*discordgo.Role
+    for _, role := range roles {
+        if role.ID == ref.ID {
+            return role, nil
+        }
+
+        if ref.Name != "" && role.Name == ref.Name {
+            named = append(named, role)
+        }
+    }
+
+    switch len(named) {
+    case 0:
+        return nil, fmt.Errorf("role \"%s\" [%s] is not found", ref.Name, ref.ID)
+    case 1:
+        return named[0], nil
+    default:
+        return nil, fmt.Errorf("%d roles are named \"%s\", use the role id", len(named), ref.Name)
+    }
+}
+
+func checkRange(setting string, value *int32, min float64, max float64) error {
+    if value == nil || (float64(*value) >= min && float64(*value) <= max) {
+        return nil
+    }
+
+    return fmt.Errorf("%s %d is not within %.0f-%.0f", setting, *value, min, max)
+}
+
+func importEnum(setting string, value *string, names map[string]int32, fallback int32) (int32, error) {
+    if value == nil {
+        return fallback, nil
+    }
+
+    number, ok := names[strings.ToLower(*value)]
+    if !ok {
+        available := make([]string, 0, len(names))
+        for name := range names {
+            available = append(available, name)
+        }
+        sort.Strings(available)
+
+        return fallback, fmt.Errorf("%s \"%s\" is not one of %s", setting, *value, strings.Join(available, ", "))
+    }
+
+    return number, nil
+}
+
+func valueOr[T any](value *T, fallback T) T {
+    if value == nil {
+        return fallback
+    }
+
+    return *value
+}
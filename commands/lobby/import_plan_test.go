@@ -0,0 +1,323 @@
+package lobby
+
+import (
+    "hometown-bot/model"
+    "strings"
+    "testing"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+func TestResolveChannel(t *testing.T) {
+    channels := []*discordgo.Channel{
+        {ID: "1", Name: "General", Type: discordgo.ChannelTypeGuildVoice},
+        {ID: "2", Name: "Gaming", Type: discordgo.ChannelTypeGuildVoice},
+        {ID: "3", Name: "Gaming", Type: discordgo.ChannelTypeGuildCategory},
+        {ID: "4", Name: "Music", Type: discordgo.ChannelTypeGuildVoice},
+        {ID: "5", Name: "Music", Type: discordgo.ChannelTypeGuildVoice},
+    }
+
+    tests := []struct {
+        name        string
+        ref         reference
+        channelType discordgo.ChannelType
+        want        string
+        wantErr     string
+    }{
+        {
+            name:        "by id",
+            ref:         reference{ID: "1", Name: "General"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            want:        "1",
+        },
+        {
+            name:        "id before name",
+            ref:         reference{ID: "1", Name: "Gaming"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            want:        "1",
+        },
+        {
+            name:        "renamed channel",
+            ref:         reference{ID: "2", Name: "Lounge"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            want:        "2",
+        },
+        {
+            name:        "by name from another guild",
+            ref:         reference{ID: "100", Name: "Gaming"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            want:        "2",
+        },
+        {
+            name:        "by name of the channel type",
+            ref:         reference{ID: "100", Name: "Gaming"},
+            channelType: discordgo.ChannelTypeGuildCategory,
+            want:        "3",
+        },
+        {
+            name:        "id of another channel type",
+            ref:         reference{ID: "3"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            wantErr:     "is not found",
+        },
+        {
+            name:        "missing",
+            ref:         reference{ID: "100", Name: "Lounge"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            wantErr:     "is not found",
+        },
+        {
+            name:        "missing without name",
+            ref:         reference{ID: "100"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            wantErr:     "is not found",
+        },
+        {
+            name:        "ambiguous name",
+            ref:         reference{ID: "100", Name: "Music"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            wantErr:     "2 channels are named",
+        },
+        {
+            name:        "ambiguous name by id",
+            ref:         reference{ID: "5", Name: "Music"},
+            channelType: discordgo.ChannelTypeGuildVoice,
+            want:        "5",
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            channel, err := resolveChannel(channels, test.ref, test.channelType)
+            if test.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+                    t.Fatalf("resolveChannel() error = %v, want %q", err, test.wantErr)
+                }
+                return
+            }
+
+            if err != nil {
+                t.Fatalf("resolveChannel() error = %v", err)
+            }
+
+            if channel.ID != test.want {
+                t.Fatalf("resolveChannel() = %s, want %s", channel.ID, test.want)
+            }
+        })
+    }
+}
+
+func TestResolveRole(t *testing.T) {
+    roles := []*discordgo.Role{
+        {ID: "1", Name: "Member"},
+        {ID: "2", Name: "Moderator"},
+        {ID: "3", Name: "Guest"},
+        {ID: "4", Name: "Guest"},
+    }
+
+    tests := []struct {
+        name    string
+        ref     reference
+        want    string
+        wantErr string
+    }{
+        {name: "by id", ref: reference{ID: "1", Name: "Member"}, want: "1"},
+        {name: "id before name", ref: reference{ID: "1", Name: "Moderator"}, want: "1"},
+        {name: "renamed role", ref: reference{ID: "2", Name: "Mod"}, want: "2"},
+        {name: "by name from another guild", ref: reference{ID: "100", Name: "Moderator"}, want: "2"},
+        {name: "missing", ref: reference{ID: "100", Name: "Admin"}, wantErr: "is not found"},
+        {name: "ambiguous name", ref: reference{ID: "100", Name: "Guest"}, wantErr: "2 roles are named"},
+        {name: "ambiguous name by id", ref: reference{ID: "4", Name: "Guest"}, want: "4"},
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            role, err := resolveRole(roles, test.ref)
+            if test.wantErr != "" {
+                if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+                    t.Fatalf("resolveRole() error = %v, want %q", err, test.wantErr)
+                }
+                return
+            }
+
+            if err != nil {
+                t.Fatalf("resolveRole() error = %v", err)
+            }
+
+            if role.ID != test.want {
+                t.Fatalf("resolveRole() = %s, want %s", role.ID, test.want)
+            }
+        })
+    }
+}
+
+func TestCheckRange(t *testing.T) {
+    value := func(v int32) *int32 { return &v }
+
+    tests := []struct {
+        name    string
+        value   *int32
+        wantErr bool
+    }{
+        {name: "unset", value: nil},
+        {name: "minimum", value: value(0)},
+        {name: "maximum", value: value(600)},
+        {name: "below", value: value(-1), wantErr: true},
+        {name: "above", value: value(601), wantErr: true},
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            if err := checkRange("cooldown", test.value, minCooldown, maxCooldown); (err != nil) != test.wantErr {
+                t.Fatalf("checkRange() error = %v, want error %t", err, test.wantErr)
+            }
+        })
+    }
+}
+
+func TestImportEnum(t *testing.T) {
+    value := func(v string) *string { return &v }
+
+    tests := []struct {
+        name    string
+        value   *string
+        want    model.TextChannel
+        wantErr string
+    }{
+        {name: "unset", value: nil, want: model.TextChannelNone},
+        {name: "name", value: value("archive"), want: model.TextChannelArchive},
+        {name: "case", value: value("Delete"), want: model.TextChannelDelete},
+        {name: "unknown", value: value("keep"), want: model.TextChannelNone, wantErr: "is not one of archive, delete, none"},
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            got, err := importEnum("text", test.value, textNames, int32(model.TextChannelNone))
+            if test.wantErr == "" && err != nil || test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+                t.Fatalf("importEnum() error = %v, want %q", err, test.wantErr)
+            }
+
+            if got != int32(test.want) {
+                t.Fatalf("importEnum() = %d, want %d", got, test.want)
+            }
+        })
+    }
+}
+
+func TestBuildLobbyPlan(t *testing.T) {
+    int32Value := func(v int32) *int32 { return &v }
+    stringValue := func(v string) *string { return &v }
+
+    lobby := &discordgo.Channel{ID: "10", Name: "Lobby", ParentID: "1", Type: discordgo.ChannelTypeGuildVoice}
+    guild := &discordgo.Guild{
+        ID: "guild",
+        Channels: []*discordgo.Channel{
+            {ID: "1", Name: "Rooms", Type: discordgo.ChannelTypeGuildCategory},
+            {ID: "2", Name: "Overflow", Type: discordgo.ChannelTypeGuildCategory},
+            lobby,
+            {ID: "11", Name: "Waiting", Type: discordgo.ChannelTypeGuildVoice},
+        },
+        Roles: []*discordgo.Role{
+            {ID: "20", Name: "Member"},
+        },
+    }
+
+    t.Run("defaults", func(t *testing.T) {
+        plan, issues := buildLobbyPlan(nil, guild, discordgo.PremiumTierNone, lobby, lobbyConfig{ID: lobby.ID, Name: lobby.Name})
+        if len(issues) > 0 {
+            t.Fatalf("buildLobbyPlan() issues = %v, want none", issues)
+        }
+
+        l := plan.lobby
+        if l.Id != lobby.ID || l.CategoryID != lobby.ParentID || l.GuildID != guild.ID {
+            t.Fatalf("buildLobbyPlan() lobby = %s/%s/%s, want %s/%s/%s", l.Id, l.CategoryID, l.GuildID, lobby.ID, lobby.ParentID, guild.ID)
+        }
+
+        if !l.Cooldown.Valid || l.Cooldown.Int32 != 0 || !l.BurstLimit.Valid || l.BurstLimit.Int32 != 0 {
+            t.Fatalf("buildLobbyPlan() cooldown = %v, burst limit = %v, want both set to 0", l.Cooldown, l.BurstLimit)
+        }
+
+        if !l.TextChannel.Valid || model.TextChannel(l.TextChannel.Int32) != model.TextChannelNone {
+            t.Fatalf("buildLobbyPlan() text = %v, want %d", l.TextChannel, model.TextChannelNone)
+        }
+    })
+
+    t.Run("references", func(t *testing.T) {
+        config := lobbyConfig{
+            ID:         lobby.ID,
+            Name:       lobby.Name,
+            Waiting:    &reference{ID: "100", Name: "Waiting"},
+            Roles:      []roleConfig{{ID: "100", Name: "Member", Allowed: true}},
+            Overwrites: []overwriteConfig{{ID: "20", Type: "role", Allow: 1024}, {ID: "30", Type: "member", Deny: 1024}},
+            Overflows:  []reference{{ID: "2"}, {ID: "100", Name: "Rooms"}},
+        }
+
+        plan, issues := buildLobbyPlan(nil, guild, discordgo.PremiumTierNone, lobby, config)
+        if len(issues) > 0 {
+            t.Fatalf("buildLobbyPlan() issues = %v, want none", issues)
+        }
+
+        if plan.lobby.WaitingID.String != "11" {
+            t.Fatalf("buildLobbyPlan() waiting = %s, want 11", plan.lobby.WaitingID.String)
+        }
+
+        if len(plan.roles) != 1 || plan.roles[0].RoleID != "20" || !plan.roles[0].Allowed {
+            t.Fatalf("buildLobbyPlan() roles = %+v, want role 20 allowed", plan.roles)
+        }
+
+        if len(plan.overwrites) != 2 || plan.overwrites[0].TargetID != "20" || plan.overwrites[1].TargetID != "30" {
+            t.Fatalf("buildLobbyPlan() overwrites = %+v, want role 20 and member 30", plan.overwrites)
+        }
+
+        if strings.Join(plan.overflows, ",") != "2,1" {
+            t.Fatalf("buildLobbyPlan() overflows = %v, want [2 1]", plan.overflows)
+        }
+    })
+
+    t.Run("issues", func(t *testing.T) {
+        config := lobbyConfig{
+            ID:         lobby.ID,
+            Name:       lobby.Name,
+            Template:   stringValue("%unknown%"),
+            Capacity:   int32Value(100),
+            Grace:      int32Value(-1),
+            Cooldown:   int32Value(601),
+            BurstLimit: int32Value(21),
+            MaxRooms:   int32Value(501),
+            Bitrate:    int32Value(384),
+            Naming:     stringValue("random"),
+            Waiting:    &reference{ID: lobby.ID},
+            Roles:      []roleConfig{{ID: "100", Name: "Admin"}},
+            Overwrites: []overwriteConfig{{ID: "20", Type: "channel"}, {ID: "30", Type: "member", Allow: 1024, Deny: 1024}},
+            Overflows:  []reference{{ID: "11"}},
+        }
+
+        _, issues := buildLobbyPlan(nil, guild, discordgo.PremiumTierNone, lobby, config)
+
+        want := []string{
+            "template: unknown placeholders",
+            "capacity 100 is not within 0-99",
+            "grace -1 is not within",
+            "cooldown 601 is not within",
+            "burst_limit 21 is not within",
+            "max_rooms 501 is not within",
+            "bitrate 384 kbps is not within",
+            "naming \"random\" is not one of",
+            "waiting: the lobby cannot be its own waiting room",
+            "roles: role \"Admin\" [100] is not found",
+            "overwrites type \"channel\" is not one of",
+            "are both allowed and denied",
+            "overflows: channel \"\" [11] is not found",
+        }
+
+        if len(issues) != len(want) {
+            t.Fatalf("buildLobbyPlan() issues = %q, want %d issues", issues, len(want))
+        }
+
+        for index, issue := range issues {
+            if !strings.Contains(issue, want[index]) {
+                t.Errorf("buildLobbyPlan() issue %d = %q, want %q", index, issue, want[index])
+            }
+        }
+    })
+}
@@ -0,0 +1,190 @@
+package lobby
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+    "sort"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// getImportPreview lists the settings the import changes, lobbies without changes are dropped from the import.
+func (lc *Command) getImportPreview(imp *lobbyImport) (string, error) {
+    var lines []string
+    if imp.guild != nil {
+        current, err := lc.guildRepository.GetGuild(imp.guildId)
+        if err != nil && !errors.Is(err, sql.ErrNoRows) {
+            return "", fmt.Errorf("db: %w", err)
+        }
+
+        lines = append(lines, fmt.Sprintf("**Server** room limit: %s → %s", getLimitName(current.MaxRooms), getLimitName(imp.guild.MaxRooms)))
+    }
+
+    changed := imp.lobbies[:0]
+    for _, plan := range imp.lobbies {
+        current := lobbyPlan{lobby: model.Lobby{Id: plan.lobby.Id}, isNew: plan.isNew}
+        if !plan.isNew {
+            var err error
+            if current, err = lc.getLobbyPlan(plan.lobby.Id, imp.guildId); err != nil {
+                return "", err
+            }
+        }
+
+        var changes []string
+        before, after := describePlan(current), describePlan(plan)
+        for index := range after {
+            if before[index][1] != after[index][1] {
+                changes = append(changes, fmt.Sprintf("`%s`: %s → %s", after[index][0], before[index][1], after[index][1]))
+            }
+        }
+
+        if plan.isNew {
+            lines = append(lines, fmt.Sprintf("**New lobby** <#%s>", plan.lobby.Id))
+        } else if len(changes) > 0 {
+            lines = append(lines, fmt.Sprintf("**Lobby** <#%s>", plan.lobby.Id))
+        } else {
+            continue
+        }
+
+        for _, change := range changes {
+            lines = append(lines, fmt.Sprintf("- %s", change))
+        }
+        changed = append(changed, plan)
+    }
+    imp.lobbies = changed
+
+    preview := strings.Join(lines, "\n")
+    if len(preview) > maxPreviewSize {
+        preview = preview[:max(strings.LastIndex(preview[:maxPreviewSize], "\n"), 0)] + "\n.."
+    }
+
+    return preview, nil
+}
+
+// getLobbyPlan loads the stored lobby in the shape of an import to compare them.
+func (lc *Command) getLobbyPlan(lobbyId string, guildId string) (lobbyPlan, error) {
+    var plan lobbyPlan
+    var err error
+
+    if plan.lobby, err = lc.lobbyRepository.GetLobby(lobbyId, guildId); err != nil {
+        return plan, fmt.Errorf("db: %w", err)
+    }
+
+    if plan.roles, err = lc.lobbyRolesRepository.GetLobbyRoles(lobbyId); err != nil {
+        return plan, fmt.Errorf("db: %w", err)
+    }
+
+    if plan.overwrites, err = lc.lobbyOverwritesRepository.GetLobbyOverwrites(lobbyId); err != nil {
+        return plan, fmt.Errorf("db: %w", err)
+    }
+
+    overflows, err := lc.lobbyOverflowsRepository.GetLobbyOverflows(lobbyId)
+    if err != nil {
+        return plan, fmt.Errorf("db: %w", err)
+    }
+
+    for _, overflow := range overflows {
+        plan.overflows = append(plan.overflows, overflow.CategoryID)
+    }
+
+    return plan, nil
+}
+
+// describePlan returns names and readable values of all lobby settings, unset settings show their defaults.
+func describePlan(plan lobbyPlan) [][2]string {
+    l := plan.lobby
+
+    template := defaultTemplate
+    if l.Template.Valid && l.Template.String != "" {
+        template = l.Template.String
+    }
+
+    capacity := "unlimited"
+    if l.Capacity.Valid && l.Capacity.Int32 > 0 {
+        capacity = fmt.Sprintf("%d", l.Capacity.Int32)
+    }
+
+    bitrate := "default"
+    if l.Bitrate.Valid && int(l.Bitrate.Int32) >= discord.MinBitrate {
+        bitrate = fmt.Sprintf("%d kbps", l.Bitrate.Int32/1000)
+    }
+
+    region := regionAutomatic
+    if l.RTCRegion.Valid && l.RTCRegion.String != "" {
+        region = l.RTCRegion.String
+    }
+
+    var roles []string
+    for _, role := range plan.roles {
+        access := "denied"
+        if role.Allowed {
+            access = "allowed"
+        }
+        roles = append(roles, fmt.Sprintf("<@&%s> %s", role.RoleID, access))
+    }
+
+    var overwrites []string
+    for _, overwrite := range plan.overwrites {
+        target := fmt.Sprintf("<@%s>", overwrite.TargetID)
+        if overwrite.Type == discordgo.PermissionOverwriteTypeRole {
+            target = fmt.Sprintf("<@&%s>", overwrite.TargetID)
+        }
+        overwrites = append(overwrites, fmt.Sprintf("%s +%d -%d", target, overwrite.Allow, overwrite.Deny))
+    }
+
+    overflows := "none"
+    if len(plan.overflows) > 0 {
+        overflows = fmt.Sprintf("<#%s>", strings.Join(plan.overflows, ">, <#"))
+    }
+
+    return [][2]string{
+        {"template", template},
+        {"capacity", capacity},
+        {"naming", describeEnum(l.Naming, namingNames, int32(model.NamingTemplate))},
+        {"grace", fmt.Sprintf("%ds", l.Grace.Int32)},
+        {"bitrate", bitrate},
+        {"region", region},
+        {"video", describeEnum(l.VideoQuality, videoNames, 0)},
+        {"nsfw", fmt.Sprintf("%t", l.NSFW.Bool)},
+        {"text", describeEnum(l.TextChannel, textNames, int32(model.TextChannelNone))},
+        {"activity", fmt.Sprintf("%t", l.Activity.Bool)},
+        {"overrides", fmt.Sprintf("%t", l.Overrides.Bool)},
+        {"cooldown", getCooldown(l).String()},
+        {"burst_limit", getLimitName(l.BurstLimit)},
+        {"block_spam", fmt.Sprintf("%t", l.BlockSpam.Bool)},
+        {"max_rooms", getLimitName(l.MaxRooms)},
+        {"waiting", getWaitingName(l)},
+        {"permissions", describeEnum(l.Permissions, permissionNames, int32(model.PermissionSourceCategory))},
+        {"placement", describeEnum(l.Placement, placementNames, int32(model.PlacementBelow))},
+        {"auto_overflow", fmt.Sprintf("%t", l.AutoOverflow.Bool)},
+        {"roles", describeList(roles)},
+        {"overwrites", describeList(overwrites)},
+        {"overflows", overflows},
+    }
+}
+
+func describeEnum(value sql.NullInt32, names map[string]int32, fallback int32) string {
+    if !value.Valid {
+        value.Int32 = fallback
+    }
+
+    if name := exportEnum(sql.NullInt32{Valid: true, Int32: value.Int32}, names); name != nil {
+        return *name
+    }
+
+    return "default"
+}
+
+// describeList joins the values in a stable order, as repositories do not keep the order of roles and overwrites.
+func describeList(values []string) string {
+    if len(values) == 0 {
+        return "none"
+    }
+
+    sort.Strings(values)
+    return strings.Join(values, ", ")
+}
@@ -0,0 +1,55 @@
+package lobby
+
+import (
+    "fmt"
+    "sync"
+)
+
+// indexReservations hands out room numbers for lobbies while their channels are being created,
+// so concurrent joins never get the same number before it is saved.
+type indexReservations struct {
+    mu       sync.Mutex
+    reserved map[string]map[int]bool // Lobby id to numbers of rooms in creation
+}
+
+// reserveIndex returns the lowest number that is neither used by a stored room nor reserved.
+func (lc *Command) reserveIndex(lobbyId string) (int, error) {
+    lc.indexes.mu.Lock()
+    defer lc.indexes.mu.Unlock()
+
+    indexes, err := lc.channelRepository.GetChannelIndexes(lobbyId)
+    if err != nil {
+        return 0, fmt.Errorf("unable to reserve index for lobby %s: %w", lobbyId, err)
+    }
+
+    used := make(map[int]bool, len(indexes))
+    for _, index := range indexes {
+        used[index] = true
+    }
+
+    if lc.indexes.reserved == nil {
+        lc.indexes.reserved = make(map[string]map[int]bool)
+    }
+
+    reserved, ok := lc.indexes.reserved[lobbyId]
+    if !ok {
+        reserved = make(map[int]bool)
+        lc.indexes.reserved[lobbyId] = reserved
+    }
+
+    index := 1
+    for used[index] || reserved[index] {
+        index++
+    }
+
+    reserved[index] = true
+    return index, nil
+}
+
+// releaseIndex frees the reservation once the room is saved or its creation failed.
+func (lc *Command) releaseIndex(lobbyId string, index int) {
+    lc.indexes.mu.Lock()
+    defer lc.indexes.mu.Unlock()
+
+    delete(lc.indexes.reserved[lobbyId], index)
+}
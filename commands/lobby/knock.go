@@ -0,0 +1,369 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandWaiting string = "waiting" // Subcommand lobby waiting
+    optionWaiting  string = "waiting" // Option for commandWaiting
+)
+
+// Custom ids of knock components are "knock:<action>:<key>"
+const (
+    knockPrefix string = "knock"  // Prefix of knock components
+    knockSelect string = "select" // Room selection of the user in the waiting room
+    knockAccept string = "accept" // Owner accepts the knock
+    knockDeny   string = "deny"   // Owner denies the knock
+)
+
+const (
+    knockTimeout  = 2 * time.Minute // Time the owner has to answer a knock
+    maxKnockRooms = 25              // Discord select menu holds 25 options at most
+)
+
+// knock is a request of the user in the waiting room to join the locked room.
+type knock struct {
+    guildId   string
+    userId    string
+    roomId    string
+    waitingId string
+    messageId string // Message with Accept and Deny buttons in the room chat
+}
+
+// knocks keeps pending knocks until the owner answers them or they time out.
+type knocks struct {
+    mu      sync.Mutex
+    pending map[string]knock // User and room key to the knock
+}
+
+/* ------ COMMANDS ------ */
+
+func getWaitingCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandWaiting,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select a waiting room to knock on locked channels of the lobby.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionWaiting,
+                Description: "A voice channel to be used as the waiting room.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandWaiting(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    waiting := options[0].Options[1].ChannelValue(s)
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: waiting command: %v", err)
+        return response
+    }
+
+    if waiting.ID == channel.ID {
+        log.Warn().Printf("lobby: waiting command: lobby %s cannot be its own waiting room", channel.ID)
+        return model.CommandWarning("The lobby cannot be its own waiting room!")
+    }
+
+    if _, err := lc.lobbyRepository.GetLobby(waiting.ID, i.GuildID); err == nil {
+        log.Warn().Printf("lobby: waiting command: %s[%s] is a lobby", waiting.Name, waiting.ID)
+        return model.CommandWarning(
+            fmt.Sprintf("\"%s\" is a lobby and cannot be a waiting room!", waiting.Name),
+        )
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        WaitingID: sql.NullString{
+            Valid:  true,
+            String: waiting.ID,
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: waiting command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update waiting room for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: waiting command: save waiting room %s for %s[%s]", waiting.ID, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("\"%s\" is now the waiting room of \"%s\".", waiting.Name, channel.Name),
+    )
+}
+
+// handleKnockComponent handles the room selection and the owner's answer to knocks.
+func (lc *Command) handleKnockComponent(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    action string,
+    key string,
+) (model.CommandResponse, bool, bool) {
+    var response model.CommandResponse
+    var isAnswered bool
+    switch action {
+    case knockSelect:
+        response, isAnswered = lc.handleKnockSelect(s, i, key, i.MessageComponentData().Values)
+    case knockAccept:
+        response, isAnswered = lc.handleKnockAnswer(s, i, key, true)
+    case knockDeny:
+        response, isAnswered = lc.handleKnockAnswer(s, i, key, false)
+    default:
+        return response, false, false
+    }
+
+    return response, isAnswered, true
+}
+
+/* ------ KNOCKS ------ */
+
+// offerRooms lets the user who joined the waiting room choose a locked room of the lobby to knock on.
+func (lc *Command) offerRooms(s *discordgo.Session, guildId string, l model.Lobby, userId string) {
+    channels, err := lc.channelRepository.GetChannels(guildId)
+    if err != nil {
+        log.Error().Printf("knock: get channels: %v", err)
+        return
+    }
+
+    var options []discordgo.SelectMenuOption
+    for _, channel := range channels {
+        if channel.ParentID != l.Id || channel.Privacy != model.PrivacyLocked || channel.OwnerID == userId {
+            continue
+        }
+
+        name := channel.Id
+        if room, err := s.State.Channel(channel.Id); err == nil {
+            name = room.Name
+        }
+
+        options = append(options, discordgo.SelectMenuOption{
+            Label: name,
+            Value: channel.Id,
+        })
+
+        if len(options) == maxKnockRooms {
+            break
+        }
+    }
+
+    waitingId := l.WaitingID.String
+    if len(options) == 0 {
+        log.Info().Printf("knock: lobby %s has no locked rooms for %s", l.Id, userId)
+        if _, err := s.ChannelMessageSend(waitingId, fmt.Sprintf("<@%s> there are no locked rooms to knock on.", userId)); err != nil {
+            log.Error().Printf("knock: API: unable to notify %s in waiting room %s: %v", userId, waitingId, err)
+        }
+        return
+    }
+
+    log.Info().Printf("knock: offer %d rooms to %s in waiting room %s", len(options), userId, waitingId)
+    if _, err := s.ChannelMessageSendComplex(waitingId, &discordgo.MessageSend{
+        Content: fmt.Sprintf("<@%s> which room would you like to join?", userId),
+        Components: []discordgo.MessageComponent{
+            discordgo.ActionsRow{
+                Components: []discordgo.MessageComponent{
+                    discordgo.SelectMenu{
+                        CustomID:    fmt.Sprintf("%s:%s:%s", knockPrefix, knockSelect, userId),
+                        Placeholder: "Choose a room to knock on",
+                        Options:     options,
+                    },
+                },
+            },
+        },
+    }); err != nil {
+        log.Error().Printf("knock: API: unable to offer rooms to %s in waiting room %s: %v", userId, waitingId, err)
+    }
+}
+
+func (lc *Command) handleKnockSelect(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    userId string,
+    values []string,
+) (model.CommandResponse, bool) {
+    if i.Member.User.ID != userId {
+        return model.CommandWarning("This is not your knock!"), false
+    }
+
+    if len(values) == 0 {
+        return model.CommandWarning("Choose a room to knock on!"), false
+    }
+
+    channel, err := lc.channelRepository.GetChannel(values[0], i.GuildID)
+    if err != nil {
+        log.Warn().Printf("knock: %v", err)
+        return model.CommandWarning("The room does not exist anymore."), true
+    }
+
+    voiceState, err := s.State.VoiceState(i.GuildID, userId)
+    if err != nil || voiceState.ChannelID != i.ChannelID {
+        return model.CommandWarning("Join the waiting room to knock!"), false
+    }
+
+    k := knock{
+        guildId:   i.GuildID,
+        userId:    userId,
+        roomId:    channel.Id,
+        waitingId: i.ChannelID,
+    }
+    key := fmt.Sprintf("%s-%s", userId, channel.Id)
+
+    message, err := s.ChannelMessageSendComplex(channel.Id, &discordgo.MessageSend{
+        Content: fmt.Sprintf("<@%s>, <@%s> is knocking on your room.", channel.OwnerID, userId),
+        Components: []discordgo.MessageComponent{
+            discordgo.ActionsRow{
+                Components: []discordgo.MessageComponent{
+                    discordgo.Button{
+                        Label:    "Accept",
+                        Style:    discordgo.SuccessButton,
+                        CustomID: fmt.Sprintf("%s:%s:%s", knockPrefix, knockAccept, key),
+                    },
+                    discordgo.Button{
+                        Label:    "Deny",
+                        Style:    discordgo.DangerButton,
+                        CustomID: fmt.Sprintf("%s:%s:%s", knockPrefix, knockDeny, key),
+                    },
+                },
+            },
+        },
+    })
+    if err != nil {
+        log.Error().Printf("knock: API: unable to knock on room %s: %v", channel.Id, err)
+        return model.CommandError("Unable to knock on the room."), true
+    }
+
+    k.messageId = message.ID
+
+    lc.knocks.mu.Lock()
+    if lc.knocks.pending == nil {
+        lc.knocks.pending = make(map[string]knock)
+    }
+    lc.knocks.pending[key] = k
+    lc.knocks.mu.Unlock()
+
+    lc.knockTimeouts.Schedule(key, knockTimeout, func() {
+        lc.expireKnock(s, key)
+    })
+
+    log.Info().Printf("knock: %s knocked on room %s", userId, channel.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("<@%s> knocked on <#%s>, waiting for the owner..", userId, channel.Id),
+    ), true
+}
+
+func (lc *Command) handleKnockAnswer(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    key string,
+    accepted bool,
+) (model.CommandResponse, bool) {
+    lc.knocks.mu.Lock()
+    k, ok := lc.knocks.pending[key]
+    lc.knocks.mu.Unlock()
+
+    if !ok {
+        return model.CommandWarning("The knock has expired."), true
+    }
+
+    channel, err := lc.channelRepository.GetChannel(k.roomId, i.GuildID)
+    if err != nil {
+        lc.removeKnock(key)
+        log.Warn().Printf("knock: %v", err)
+        return model.CommandWarning("The room does not exist anymore."), true
+    }
+
+    if i.Member.User.ID != channel.OwnerID {
+        return model.CommandWarning("Only the room owner can answer knocks!"), false
+    }
+
+    lc.removeKnock(key)
+
+    if !accepted {
+        log.Info().Printf("knock: owner %s denied %s in room %s", channel.OwnerID, k.userId, k.roomId)
+        return model.CommandSuccess(fmt.Sprintf("<@%s> was not let in.", k.userId)), true
+    }
+
+    voiceState, err := s.State.VoiceState(k.guildId, k.userId)
+    if err != nil || voiceState.ChannelID != k.waitingId {
+        log.Info().Printf("knock: %s left the waiting room %s", k.userId, k.waitingId)
+        return model.CommandWarning(fmt.Sprintf("<@%s> is not in the waiting room anymore.", k.userId)), true
+    }
+
+    if err := s.GuildMemberMove(k.guildId, k.userId, &k.roomId); err != nil {
+        log.Error().Printf("knock: API: unable to move %s to room %s: %v", k.userId, k.roomId, err)
+        return model.CommandError(fmt.Sprintf("Unable to move <@%s> to the room.", k.userId)), true
+    }
+
+    log.Info().Printf("knock: owner %s accepted %s in room %s", channel.OwnerID, k.userId, k.roomId)
+    return model.CommandSuccess(fmt.Sprintf("<@%s> was let in.", k.userId)), true
+}
+
+// expireKnock removes the buttons of the knock the owner did not answer in time.
+func (lc *Command) expireKnock(s *discordgo.Session, key string) {
+    lc.knocks.mu.Lock()
+    k, ok := lc.knocks.pending[key]
+    delete(lc.knocks.pending, key)
+    lc.knocks.mu.Unlock()
+
+    if !ok {
+        return
+    }
+
+    log.Info().Printf("knock: knock of %s on room %s timed out", k.userId, k.roomId)
+    content := fmt.Sprintf("Knock of <@%s> timed out.", k.userId)
+    if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+        ID:         k.messageId,
+        Channel:    k.roomId,
+        Content:    &content,
+        Components: &[]discordgo.MessageComponent{},
+    }); err != nil && !isUnknownChannel(err) {
+        log.Error().Printf("knock: API: unable to expire knock message %s: %v", k.messageId, err)
+    }
+}
+
+func (lc *Command) removeKnock(key string) {
+    lc.knockTimeouts.Cancel(key)
+
+    lc.knocks.mu.Lock()
+    delete(lc.knocks.pending, key)
+    lc.knocks.mu.Unlock()
+}
+
+// findWaitingLobby returns the lobby which waiting room is the channel.
+func findWaitingLobby(lobbies []model.Lobby, channelId string) (model.Lobby, bool) {
+    for _, l := range lobbies {
+        if l.WaitingID.Valid && l.WaitingID.String != "" && l.WaitingID.String == channelId {
+            return l, true
+        }
+    }
+
+    return model.Lobby{}, false
+}
+
+func getWaitingName(l model.Lobby) string {
+    if !l.WaitingID.Valid || l.WaitingID.String == "" {
+        return "none"
+    }
+
+    return fmt.Sprintf("<#%s>", l.WaitingID.String)
+}
@@ -0,0 +1,211 @@
+package lobby
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandLimit      string = "limit" // Subcommand group lobby limit
+    commandLimitLobby string = "lobby" // Subcommand lobby limit lobby
+    commandLimitGuild string = "guild" // Subcommand lobby limit guild
+    optionRooms       string = "rooms" // Option for commandLimitLobby, commandLimitGuild
+)
+
+var (
+    minRooms      float64 = 0   // Zero means unlimited rooms
+    maxLobbyRooms float64 = 500 // Overflow categories let a lobby grow up to the guild limit
+    maxGuildRooms float64 = 500 // Discord guild holds 500 channels at most
+)
+
+/* ------ COMMANDS ------ */
+
+func getLimitCommandGroup() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandLimit,
+        Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+        Description: "Limits of active channels.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Name:        commandLimitLobby,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Select how many channels of the lobby can be active at once.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionInteger,
+                        Name:        optionRooms,
+                        Description: "A maximum number of active channels, 0 is unlimited.",
+                        MinValue:    &minRooms,
+                        MaxValue:    maxLobbyRooms,
+                        Required:    true,
+                    },
+                },
+            },
+            {
+                Name:        commandLimitGuild,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Select how many channels of all lobbies can be active at once.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    {
+                        Type:        discordgo.ApplicationCommandOptionInteger,
+                        Name:        optionRooms,
+                        Description: "A maximum number of active channels, 0 is unlimited.",
+                        MinValue:    &minRooms,
+                        MaxValue:    maxGuildRooms,
+                        Required:    true,
+                    },
+                },
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandLimit(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    subcommand := i.ApplicationCommandData().Options[0].Options[0]
+
+    if subcommand.Name == commandLimitGuild {
+        rooms := subcommand.Options[0].IntValue()
+
+        guild := model.Guild{
+            Id: i.GuildID,
+            MaxRooms: sql.NullInt32{
+                Valid: true,
+                Int32: int32(rooms),
+            },
+        }
+
+        if err := lc.guildRepository.UpsertGuild(&guild); err != nil {
+            log.Error().Printf("lobby: limit guild command: unable to update guild %s: %v", i.GuildID, err)
+            return model.CommandError("Unable to update the room limit of this server.")
+        }
+
+        log.Info().Printf("lobby: limit guild command: save limit %d for guild %s", rooms, i.GuildID)
+        return model.CommandSuccess(
+            fmt.Sprintf("Room limit %s successfully set for this server.", getLimitName(guild.MaxRooms)),
+        )
+    }
+
+    channel := subcommand.Options[0].ChannelValue(s)
+    rooms := subcommand.Options[1].IntValue()
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: limit lobby command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        MaxRooms: sql.NullInt32{
+            Valid: true,
+            Int32: int32(rooms),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: limit lobby command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update room limit for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: limit lobby command: save limit %d for %s[%s]", rooms, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Room limit %s successfully set for \"%s\".", getLimitName(lobby.MaxRooms), channel.Name),
+    )
+}
+
+/* ------ LIMITS ------ */
+
+// getActiveRooms returns the number of active rooms of the lobby and of all lobbies of the guild.
+func (lc *Command) getActiveRooms(guildId string, lobbyId string) (int, int, error) {
+    lobbies, err := lc.lobbyRepository.GetLobbies(guildId)
+    if err != nil {
+        return 0, 0, fmt.Errorf("db: %w", err)
+    }
+
+    guildLobbies := make(map[string]bool, len(lobbies))
+    for _, l := range lobbies {
+        guildLobbies[l.Id] = true
+    }
+
+    channels, err := lc.channelRepository.GetChannels(guildId)
+    if err != nil {
+        return 0, 0, fmt.Errorf("db: %w", err)
+    }
+
+    var lobbyRooms, guildRooms int
+    for _, channel := range channels {
+        if channel.ParentID == lobbyId {
+            lobbyRooms++
+        }
+
+        if guildLobbies[channel.ParentID] {
+            guildRooms++
+        }
+    }
+
+    return lobbyRooms, guildRooms, nil
+}
+
+// checkRoomLimits returns a notice for the user if the lobby or the guild has no room for another channel.
+func (lc *Command) checkRoomLimits(guildId string, l model.Lobby) (string, bool) {
+    lobbyRooms, guildRooms, err := lc.getActiveRooms(guildId, l.Id)
+    if err != nil {
+        log.Error().Printf("voice updates: unable to count active rooms: %v", err)
+        return "", true
+    }
+
+    if isLimitReached(l.MaxRooms, lobbyRooms) {
+        log.Warn().Printf("voice updates: lobby %s reached its limit of %d rooms", l.Id, l.MaxRooms.Int32)
+        return fmt.Sprintf("All %d rooms of <#%s> are taken, try again later.", l.MaxRooms.Int32, l.Id), false
+    }
+
+    guild, err := lc.guildRepository.GetGuild(guildId)
+    if err != nil && !errors.Is(err, sql.ErrNoRows) {
+        log.Error().Printf("voice updates: %v", err)
+    }
+
+    if isLimitReached(guild.MaxRooms, guildRooms) {
+        log.Warn().Printf("voice updates: guild %s reached its limit of %d rooms", guildId, guild.MaxRooms.Int32)
+        return fmt.Sprintf("All %d rooms of the server are taken, try again later.", guild.MaxRooms.Int32), false
+    }
+
+    return "", true
+}
+
+// notifyUser sends the notice to the user in DM, or to the lobby chat if their DMs are closed.
+func notifyUser(s *discordgo.Session, lobbyId string, userId string, notice string) {
+    dm, err := s.UserChannelCreate(userId)
+    if err == nil {
+        if _, err = s.ChannelMessageSend(dm.ID, notice); err == nil {
+            return
+        }
+    }
+
+    log.Warn().Printf("voice updates: unable to DM user %s, notify in lobby %s: %v", userId, lobbyId, err)
+    if _, err := s.ChannelMessageSend(lobbyId, fmt.Sprintf("<@%s> %s", userId, notice)); err != nil {
+        log.Error().Printf("voice updates: unable to notify user %s in lobby %s: %v", userId, lobbyId, err)
+    }
+}
+
+func isLimitReached(limit sql.NullInt32, rooms int) bool {
+    return limit.Valid && limit.Int32 > 0 && rooms >= int(limit.Int32)
+}
+
+func getLimitName(limit sql.NullInt32) string {
+    if !limit.Valid || limit.Int32 == 0 {
+        return "unlimited"
+    }
+
+    return fmt.Sprintf("%d", limit.Int32)
+}
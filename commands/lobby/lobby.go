@@ -8,6 +8,9 @@ import (
     "hometown-bot/log"
     "hometown-bot/model"
     "hometown-bot/repository"
+    "hometown-bot/util/discord"
+    "hometown-bot/util/placeholder"
+    "hometown-bot/util/scheduler"
     "strconv"
     "strings"
 
@@ -21,10 +24,20 @@ const (
     commandName     string = "name"     // Subcommand channel name
     commandList     string = "list"     // Subcommand lobby list
     commandRemove   string = "remove"   // Subcommand lobby remove
+    commandNaming   string = "naming"   // Subcommand channel naming mode
+    commandGrace    string = "grace"    // Subcommand channel grace period
     optionChannel   string = "channel"  // Option for commandRegister
     optionLobby     string = "lobby"    // Option for commandCapacity, commandName, commandRemove
     optionCapacity  string = "capacity" // Option for commandCapacity
     optionName      string = "name"     // Option for commandName
+    optionMode      string = "mode"     // Option for commandNaming
+    optionSeconds   string = "seconds"  // Option for commandGrace
+    optionPage      string = "page"     // Option for commandList
+)
+
+const (
+    defaultTemplate  string = "Кімната %nickname%" // Room name for lobbies without template
+    numberedTemplate string = "%lobby%"            // Room name for numbered lobbies without template
 )
 
 var (
@@ -33,29 +46,87 @@ var (
     Commands                       = getLobbyCommandGroup()           // Command group
 )
 
+var (
+    minGracePeriod float64 = 0    // Empty channels are deleted immediately
+    maxGracePeriod float64 = 3600 // Empty channels are kept for an hour at most
+    minPage        float64 = 1    // Pages of the lobby list start at one
+)
+
+const (
+    lobbiesPerPage = 5    // Lobbies of a list page, keeps the embed under the Discord limit of 6000 characters
+    maxFieldSize   = 1024 // Characters of an embed field value
+)
+
 type Command struct {
-    channelRepository        repository.ChannelRepository
-    channelMembersRepository repository.ChannelMembersRepository
-    lobbyRepository          repository.LobbyRepository
-    commandHandlers          map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) // Command interaction
+    indexes                   indexReservations
+    deletions                 *scheduler.Scheduler
+    renames                   *scheduler.Scheduler
+    history                   renameHistory
+    limiter                   creationLimiter
+    knocks                    knocks
+    reconciles                reconciles
+    knockTimeouts             *scheduler.Scheduler
+    imports                   imports
+    importTimeouts            *scheduler.Scheduler
+    channelRepository         repository.ChannelRepository
+    channelMembersRepository  repository.ChannelMembersRepository
+    channelPermitsRepository  repository.ChannelPermitsRepository
+    lobbyRepository           repository.LobbyRepository
+    userPreferencesRepository repository.UserPreferencesRepository
+    guildRepository           repository.GuildRepository
+    lobbyRolesRepository      repository.LobbyRolesRepository
+    roomBansRepository        repository.RoomBansRepository
+    lobbyOverwritesRepository repository.LobbyOverwritesRepository
+    lobbyOverflowsRepository  repository.LobbyOverflowsRepository
+    presences                 bool // Whether presences are received, games are unknown without them
+    transactor                repository.Transactor
+    commandHandlers           map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) // Command interaction
+    componentHandlers         map[string]componentHandler                                           // Custom id prefix
 }
 
 func New(
     channelRepository repository.ChannelRepository,
     channelMembersRepository repository.ChannelMembersRepository,
+    channelPermitsRepository repository.ChannelPermitsRepository,
     lobbyRepository repository.LobbyRepository,
+    userPreferencesRepository repository.UserPreferencesRepository,
+    guildRepository repository.GuildRepository,
+    lobbyRolesRepository repository.LobbyRolesRepository,
+    roomBansRepository repository.RoomBansRepository,
+    lobbyOverwritesRepository repository.LobbyOverwritesRepository,
+    lobbyOverflowsRepository repository.LobbyOverflowsRepository,
+    transactor repository.Transactor,
+    presences bool,
 ) *Command {
     commands := Command{
-        channelRepository:        channelRepository,
-        channelMembersRepository: channelMembersRepository,
-        lobbyRepository:          lobbyRepository,
+        deletions:                 scheduler.New(),
+        renames:                   scheduler.New(),
+        knockTimeouts:             scheduler.New(),
+        importTimeouts:            scheduler.New(),
+        channelRepository:         channelRepository,
+        channelMembersRepository:  channelMembersRepository,
+        channelPermitsRepository:  channelPermitsRepository,
+        lobbyRepository:           lobbyRepository,
+        userPreferencesRepository: userPreferencesRepository,
+        guildRepository:           guildRepository,
+        lobbyRolesRepository:      lobbyRolesRepository,
+        roomBansRepository:        roomBansRepository,
+        lobbyOverwritesRepository: lobbyOverwritesRepository,
+        lobbyOverflowsRepository:  lobbyOverflowsRepository,
+        transactor:                transactor,
+        presences:                 presences,
     }
 
     commands.commandHandlers = commands.createCommandHandlers()
+    commands.componentHandlers = commands.createComponentHandlers()
     return &commands
 }
 
 func (lc *Command) HandleSlashCommands(discord *discordgo.Session, interaction *discordgo.InteractionCreate) {
+    if interaction.Type != discordgo.InteractionApplicationCommand {
+        return
+    }
+
     if handler, ok := lc.commandHandlers[interaction.ApplicationCommandData().Name]; ok {
         handler(discord, interaction)
     }
@@ -63,12 +134,14 @@ func (lc *Command) HandleSlashCommands(discord *discordgo.Session, interaction *
 
 // FIXME: split into small functions
 func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.VoiceStateUpdate) {
-    channels, err := lc.channelRepository.GetChannels()
+    channels, err := lc.channelRepository.GetChannels(event.GuildID)
     if err != nil {
         log.Error().Printf("voice updates: get channels: %v", err)
     }
 
-    isSomeoneLeftVoiceChannel := event.BeforeUpdate != nil && event.BeforeUpdate.ChannelID != ""
+    isChannelChanged := event.BeforeUpdate == nil || event.BeforeUpdate.ChannelID != event.ChannelID
+
+    isSomeoneLeftVoiceChannel := event.BeforeUpdate != nil && event.BeforeUpdate.ChannelID != "" && isChannelChanged
     if isSomeoneLeftVoiceChannel {
         for _, channel := range channels {
             channelId := event.BeforeUpdate.ChannelID
@@ -80,11 +153,18 @@ func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.Voi
                 if err := lc.channelMembersRepository.DeleteChannelMember(event.GuildID, userId, channel.Id); err != nil {
                     log.Error().Printf("voice updates: delete member count: %v", err)
                 }
+
+                revokeTextChannel(s, channel, userId)
+                lc.scheduleRename(s, event.GuildID, channel)
+
+                if channel.OwnerID == userId {
+                    lc.transferRoomOwnership(s, event.GuildID, channel)
+                }
             }
         }
     }
 
-    isSomeoneJoinVoiceChannel := event.VoiceState != nil && event.VoiceState.ChannelID != ""
+    isSomeoneJoinVoiceChannel := event.VoiceState != nil && event.VoiceState.ChannelID != "" && isChannelChanged
     if isSomeoneJoinVoiceChannel {
         for _, channel := range channels {
             channelId := event.VoiceState.ChannelID
@@ -96,11 +176,16 @@ func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.Voi
                 if err := lc.channelMembersRepository.SetChannelMember(event.GuildID, userId, channelId); err != nil {
                     log.Error().Printf("voice updates: insert member count: %v", err)
                 }
+
+                grantTextChannel(s, channel, userId)
+                lc.scheduleRename(s, event.GuildID, channel)
+
+                lc.cancelDeletion(channel)
             }
         }
     }
 
-    channels, err = lc.channelRepository.GetChannels()
+    channels, err = lc.channelRepository.GetChannels(event.GuildID)
     if err != nil {
         log.Error().Printf("voice updates: get channels: %v", err)
     }
@@ -120,22 +205,7 @@ func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.Voi
 
         shouldDeleteSelfDestructingChannel := channelMembersCount == 0
         if shouldDeleteSelfDestructingChannel {
-            log.Info().Printf("voice updates: channel %s is empty, deleting..", channel.Id)
-
-            if _, err := s.ChannelDelete(channel.Id); err != nil {
-                log.Error().Printf("voice updates: API: unable to delete channel %s: %v", channel.Id, err)
-                continue
-            }
-
-            if err := lc.channelRepository.DeleteChannel(channel.Id); err != nil {
-                log.Error().Printf("voice updates: db: unable to delete channel %s: %v", channel.Id, err)
-                continue
-            }
-
-            if err := lc.channelMembersRepository.DeleteChannelMembers(event.GuildID, channel.Id); err != nil {
-                log.Error().Printf("voice updates: db: unable to delete channel members %s: %v", channel.Id, err)
-                continue
-            }
+            lc.deleteEmptyChannel(s, event.GuildID, channel)
         }
     }
 
@@ -157,56 +227,116 @@ func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.Voi
         log.Error().Printf("voice updates: get lobbies: %v", err)
     }
 
+    if l, ok := findWaitingLobby(lobbies, event.ChannelID); ok {
+        lc.offerRooms(s, event.GuildID, l, event.Member.User.ID)
+        return
+    }
+
     for _, l := range lobbies {
         hasLobby := l.Id == event.ChannelID
 
         if hasLobby {
-            userName := event.Member.User.Username
-            nickname := event.Member.Nick
-            name := ""
-
-            if len(nickname) == 0 {
-                name = userName
-            } else {
-                name = nickname
+            if response, err := commands.HasLobbyAccess(lc.lobbyRolesRepository, l.Id, event.Member); err != nil {
+                log.Warn().Printf("voice updates: %v", err)
+                notifyUser(s, l.Id, event.Member.User.ID, response.Description)
+                continue
+            }
+
+            if notice, ok := lc.checkRoomLimits(event.GuildID, l); !ok {
+                notifyUser(s, l.Id, event.Member.User.ID, notice)
+                continue
+            }
+
+            if notice, ok := lc.allowCreation(l, event.Member.User.ID); !ok {
+                if !lc.moveToExistingRoom(s, event.GuildID, l, event.Member.User.ID) {
+                    notifyUser(s, l.Id, event.Member.User.ID, notice)
+                }
+                continue
+            }
+
+            categoryId, err := lc.getRoomCategory(s, event.GuildID, l)
+            if err != nil {
+                log.Error().Printf("voice updates: %v", err)
+                notifyUser(s, l.Id, event.Member.User.ID, fmt.Sprintf("All categories of <#%s> are full, try again later.", l.Id))
+                continue
             }
 
-            if l.Template.Valid && l.Template.String != "" {
-                name = fmt.Sprintf("%s %s", l.Template.String, name)
-            } else {
-                name = fmt.Sprintf("Кімната %s", name)
+            index, err := lc.reserveIndex(l.Id)
+            if err != nil {
+                log.Error().Printf("voice updates: %v", err)
+                continue
             }
 
+            name := lc.getChannelName(s, event.GuildID, event.Member, l, index)
+
             userLimit := 0
             if l.Capacity.Valid {
                 userLimit = int(l.Capacity.Int32)
             }
 
-            data := discordgo.GuildChannelCreateData{
-                Name:      name,
-                Type:      discordgo.ChannelTypeGuildVoice,
-                ParentID:  l.CategoryID,
-                UserLimit: userLimit,
+            preference, hasPreference := lc.getUserPreference(l, event.GuildID, event.Member.User.ID)
+            if hasPreference {
+                log.Info().Printf("voice updates: apply saved preferences of %s", event.Member.User.ID)
+                name = preference.Name
+                userLimit = preference.UserLimit
             }
 
+            data := getVoiceSettings(s, event.GuildID, l)
+            data.Name = name
+            data.Type = discordgo.ChannelTypeGuildVoice
+            data.ParentID = categoryId
+            data.UserLimit = userLimit
+            data.PermissionOverwrites = lc.getRoomOverwrites(s, l, categoryId, event.Member.User.ID)
+
             log.Info().Printf("voice updates: creating self-destructing channel %s", name)
 
-            newChannel, err := s.GuildChannelCreateComplex(event.GuildID, data)
+            var undo rollback
+            newChannel, err := discord.CreateVoiceChannel(s, event.GuildID, data)
             if err != nil {
                 log.Error().Printf("voice updates: unable to create self-destructing channel: %v", err)
+                lc.releaseIndex(l.Id, index)
                 continue
             }
+            undo.add(undoChannel(s, newChannel.ID))
+
+            textId, err := createTextChannel(s, event.GuildID, l, categoryId, name, event.Member.User.ID)
+            if err != nil {
+                log.Error().Printf("voice updates: unable to create text channel: %v", err)
+            } else if textId != "" {
+                undo.add(undoChannel(s, textId))
+            }
 
             channel := model.Channel{
                 Id:       newChannel.ID,
                 ParentID: l.Id,
+                GuildID:  event.GuildID,
+                OwnerID:  event.Member.User.ID,
+                Index:    index,
+                TextID:   textId,
+            }
+
+            if hasPreference {
+                log.Info().Printf("voice updates: restore saved privacy and permits of %s", event.Member.User.ID)
+                channel.Privacy = preference.Privacy
             }
 
-            if err := lc.channelRepository.SetChannel(&channel); err != nil {
+            permits := lc.getRoomPermits(event.GuildID, channel, preference, hasPreference)
+
+            err = lc.saveRoom(channel, permits)
+            lc.releaseIndex(l.Id, index)
+            if err != nil {
                 log.Error().Printf("voice updates: unable to save self-destructing channel: %v", err)
-                return
+                undo.run()
+                continue
+            }
+            lc.recordCreation(l, event.Member.User.ID)
+
+            if err := commands.ApplyRoomPermissions(s, event.GuildID, channel, permits); err != nil {
+                log.Error().Printf("voice updates: unable to apply permissions of channel %s: %v", channel.Id, err)
             }
 
+            lc.placeRoom(s, event.GuildID, l, newChannel)
+
             log.Info().Printf(
                 "voice updates: move channel creator %s[%s] to the channel %s",
                 event.Member.User.Username,
@@ -216,18 +346,143 @@ func (lc *Command) HandleVoiceUpdates(s *discordgo.Session, event *discordgo.Voi
 
             if err := s.GuildMemberMove(event.GuildID, event.Member.User.ID, &newChannel.ID); err != nil {
                 log.Error().Printf(
-                    "voice updates: unable to move channel creator %s[%s] to the channel %s: %v",
+                    "voice updates: unable to move channel creator %s[%s] to the channel %s, deleting it: %v",
                     event.Member.User.Username,
                     event.Member.User.ID,
                     name,
                     err,
                 )
+
+                if err := lc.discardRoom(event.GuildID, channel); err != nil {
+                    log.Error().Printf("voice updates: %v", err)
+                    continue
+                }
+
+                undo.run()
                 continue
             }
         }
     }
 }
 
+func (lc *Command) transferRoomOwnership(s *discordgo.Session, guildId string, channel model.Channel) {
+    members, err := lc.channelMembersRepository.GetChannelMembers(guildId, channel.Id)
+    if err != nil {
+        log.Error().Printf("voice updates: get channel members: %v", err)
+        return
+    }
+
+    if len(members) == 0 {
+        log.Info().Printf("voice updates: owner left empty channel %s, nobody to transfer", channel.Id)
+        return
+    }
+
+    newOwnerId := members[0]
+    log.Info().Printf("voice updates: owner %s left the channel %s, transfer to %s", channel.OwnerID, channel.Id, newOwnerId)
+
+    if err := commands.TransferRoom(s, lc.channelRepository, channel, newOwnerId); err != nil {
+        log.Error().Printf("voice updates: unable to transfer channel %s to %s: %v", channel.Id, newOwnerId, err)
+    }
+}
+
+func (lc *Command) getChannelName(
+    s *discordgo.Session,
+    guildId string,
+    member *discordgo.Member,
+    l model.Lobby,
+    index int,
+) string {
+    hasTemplate := l.Template.Valid && l.Template.String != ""
+
+    var template string
+    switch {
+    case l.Naming.Valid && model.Naming(l.Naming.Int32) == model.NamingNumbered:
+        template = numberedTemplate
+        if hasTemplate {
+            template = l.Template.String
+        }
+
+        if !strings.Contains(strings.ToLower(template), placeholder.Index) {
+            template = fmt.Sprintf("%s #%s", template, placeholder.Index)
+        }
+    case hasTemplate:
+        template = l.Template.String
+    default:
+        template = defaultTemplate
+    }
+
+    // Templates created before placeholders were introduced always had the name appended
+    if !placeholder.HasPlaceholders(template) {
+        template = fmt.Sprintf("%s %s", template, placeholder.Nickname)
+    }
+
+    user := member.User
+    nickname := member.Nick
+    if nickname == "" {
+        nickname = user.Username
+    }
+
+    displayName := member.Nick
+    if displayName == "" {
+        displayName = user.GlobalName
+    }
+    if displayName == "" {
+        displayName = user.Username
+    }
+
+    lobbyName := ""
+    if lobbyChannel, err := s.State.Channel(l.Id); err == nil {
+        lobbyName = lobbyChannel.Name
+    }
+
+    roomsCount := 1
+    channels, err := lc.channelRepository.GetChannels(guildId)
+    if err != nil {
+        log.Error().Printf("voice updates: get channels: %v", err)
+    }
+    for _, channel := range channels {
+        if channel.ParentID == l.Id {
+            roomsCount++
+        }
+    }
+
+    name := placeholder.Render(template, placeholder.Values{
+        Username:    user.Username,
+        Nickname:    nickname,
+        DisplayName: displayName,
+        Game:        lc.getGame(s, guildId, user.ID),
+        Index:       index,
+        Lobby:       lobbyName,
+        Count:       roomsCount,
+    })
+
+    if name == "" {
+        return fmt.Sprintf("Кімната %s", nickname)
+    }
+
+    return name
+}
+
+// getGame returns the game the user is playing, empty without presences.
+func (lc *Command) getGame(s *discordgo.Session, guildId string, userId string) string {
+    if !lc.presences {
+        return ""
+    }
+
+    presence, err := s.State.Presence(guildId, userId)
+    if err != nil {
+        return ""
+    }
+
+    for _, activity := range presence.Activities {
+        if activity.Type == discordgo.ActivityTypeGame {
+            return activity.Name
+        }
+    }
+
+    return ""
+}
+
 /* ------ COMMANDS ------ */
 
 func getLobbyCommandGroup() []*discordgo.ApplicationCommand {
@@ -243,6 +498,21 @@ func getLobbyCommandGroup() []*discordgo.ApplicationCommand {
                 getNameCommand(),
                 getListCommand(),
                 getRemoveCommand(),
+                getNamingCommand(),
+                getGraceCommand(),
+                getVoiceCommandGroup(),
+                getTextCommand(),
+                getActivityCommand(),
+                getOverridesCommand(),
+                getCooldownCommand(),
+                getLimitCommandGroup(),
+                getRolesCommandGroup(),
+                getWaitingCommand(),
+                getPermissionsCommandGroup(),
+                getPlacementCommand(),
+                getOverflowCommandGroup(),
+                getExportCommand(),
+                getImportCommand(),
             },
         },
     }
@@ -310,7 +580,7 @@ func getNameCommand() *discordgo.ApplicationCommandOption {
             {
                 Type:        discordgo.ApplicationCommandOptionString,
                 Name:        optionName,
-                Description: "A new channels' name when created.",
+                Description: "A new channels' name when created, supports placeholders like %nickname%.",
                 Required:    true,
             },
         },
@@ -322,6 +592,14 @@ func getListCommand() *discordgo.ApplicationCommandOption {
         Name:        commandList,
         Type:        discordgo.ApplicationCommandOptionSubCommand,
         Description: "Show registered lobbies.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionPage,
+                Description: "A page of the list, the first one by default.",
+                MinValue:    &minPage,
+            },
+        },
     }
 }
 
@@ -344,6 +622,68 @@ func getRemoveCommand() *discordgo.ApplicationCommandOption {
     }
 }
 
+func getNamingCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandNaming,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select how new channels are named.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionLobby,
+                Description: "A lobby to be configured.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionMode,
+                Description: "A new channels' naming mode.",
+                Choices: []*discordgo.ApplicationCommandOptionChoice{
+                    {
+                        Name:  "Template",
+                        Value: model.NamingTemplate,
+                    },
+                    {
+                        Name:  "Numbered",
+                        Value: model.NamingNumbered,
+                    },
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+func getGraceCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandGrace,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select how long empty channels are kept before deletion.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionLobby,
+                Description: "A lobby to be configured.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionSeconds,
+                Description: "A new grace period in seconds.",
+                MinValue:    &minGracePeriod,
+                MaxValue:    maxGracePeriod,
+                Required:    true,
+            },
+        },
+    }
+}
+
 /* ------ INTERACTIONS ------ */
 
 func (lc *Command) createCommandHandlers() map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -367,6 +707,38 @@ func (lc *Command) createCommandHandlers() map[string]func(s *discordgo.Session,
                 commandResponse = lc.handleCommandList(s, i)
             case commandRemove:
                 commandResponse = lc.handleCommandRemove(s, i)
+            case commandNaming:
+                commandResponse = lc.handleCommandNaming(s, i)
+            case commandGrace:
+                commandResponse = lc.handleCommandGrace(s, i)
+            case commandVoice:
+                commandResponse = lc.handleCommandVoice(s, i)
+            case commandText:
+                commandResponse = lc.handleCommandText(s, i)
+            case commandActivity:
+                commandResponse = lc.handleCommandActivity(s, i)
+            case commandOverrides:
+                commandResponse = lc.handleCommandOverrides(s, i)
+            case commandCooldown:
+                commandResponse = lc.handleCommandCooldown(s, i)
+            case commandLimit:
+                commandResponse = lc.handleCommandLimit(s, i)
+            case commandRoles:
+                commandResponse = lc.handleCommandRoles(s, i)
+            case commandWaiting:
+                commandResponse = lc.handleCommandWaiting(s, i)
+            case commandPermissions:
+                commandResponse = lc.handleCommandPermissions(s, i)
+            case commandPlacement:
+                commandResponse = lc.handleCommandPlacement(s, i)
+            case commandOverflow:
+                commandResponse = lc.handleCommandOverflow(s, i)
+            case commandExport:
+                lc.handleCommandExport(s, i)
+                return
+            case commandImport:
+                lc.handleCommandImport(s, i)
+                return
             }
 
             log.Info().Printf("lobby: sending interaction response for %s", slashCommand)
@@ -463,6 +835,18 @@ func (lc *Command) handleCommandName(s *discordgo.Session, i *discordgo.Interact
         return response
     }
 
+    if err := placeholder.Validate(name); err != nil {
+        log.Warn().Printf("lobby: name command: invalid name %s for %s[%s]: %v", name, channel.Name, channel.ID, err)
+        return model.CommandWarning(
+            fmt.Sprintf(
+                "Name \"%s\" has %s.\nSupported placeholders: %s.",
+                name,
+                err,
+                strings.Join(placeholder.Supported, ", "),
+            ),
+        )
+    }
+
     lobby := model.Lobby{
         Id:         channel.ID,
         CategoryID: channel.ParentID,
@@ -487,12 +871,28 @@ func (lc *Command) handleCommandName(s *discordgo.Session, i *discordgo.Interact
     }
 
     log.Info().Printf("lobby: name command: save name %s for %s[%s]", name, channel.Name, lobby.Id)
+    if !lc.presences && strings.Contains(strings.ToLower(name), placeholder.Game) {
+        return model.CommandWarning(
+            fmt.Sprintf(
+                "Name \"%s\" is set for \"%s\", but %s stays empty, the bot runs without presences.",
+                name,
+                channel.Name,
+                placeholder.Game,
+            ),
+        )
+    }
+
     return model.CommandSuccess(
         fmt.Sprintf("Name \"%s\" successfully set for \"%s\".", name, channel.Name),
     )
 }
 
 func (lc *Command) handleCommandList(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    page := 1
+    if options := i.ApplicationCommandData().Options[0].Options; len(options) > 0 {
+        page = int(options[0].IntValue())
+    }
+
     lobbies, err := lc.lobbyRepository.GetLobbies(i.GuildID)
     if err != nil {
         log.Error().Printf("lobby: list command: unable to get lobby list for Guild[%s]: %v", i.GuildID, err)
@@ -501,8 +901,20 @@ func (lc *Command) handleCommandList(s *discordgo.Session, i *discordgo.Interact
         )
     }
 
-    var registeredChannels []string
-    for i, lobby := range lobbies {
+    if len(lobbies) == 0 {
+        log.Warn().Println("lobby: list command: there are no registered channels")
+        return model.CommandWarning("There are no active lobbies.")
+    }
+
+    pages := (len(lobbies) + lobbiesPerPage - 1) / lobbiesPerPage
+    if page > pages {
+        log.Warn().Printf("lobby: list command: page %d of %d requested", page, pages)
+        return model.CommandWarning(fmt.Sprintf("There are only %d pages of lobbies.", pages))
+    }
+
+    first := (page - 1) * lobbiesPerPage
+    var fields []*discordgo.MessageEmbedField
+    for index, lobby := range lobbies[first:min(first+lobbiesPerPage, len(lobbies))] {
         channel, err := s.Channel(lobby.Id)
         if err != nil {
             log.Error().Printf("lobby: list command: unable to get channels: %v", err)
@@ -512,47 +924,91 @@ func (lc *Command) handleCommandList(s *discordgo.Session, i *discordgo.Interact
             )
         }
 
-        if channel.ID == lobby.Id {
-            var template string
-            if lobby.Template.Valid && len(lobby.Template.String) > 0 {
-                template = lobby.Template.String
-            } else {
-                template = "Кімната %username%"
-            }
+        var template string
+        if lobby.Template.Valid && len(lobby.Template.String) > 0 {
+            template = lobby.Template.String
+        } else {
+            template = defaultTemplate
+        }
 
-            var capacity string
-            if lobby.Capacity.Valid {
-                capacity = strconv.FormatInt(int64(lobby.Capacity.Int32), 10)
-            } else if lobby.Capacity.Int32 == 0 {
-                capacity = "unlimited"
-            } else {
-                capacity = "unlimited"
-            }
+        capacity := "unlimited"
+        if lobby.Capacity.Valid && lobby.Capacity.Int32 > 0 {
+            capacity = strconv.FormatInt(int64(lobby.Capacity.Int32), 10)
+        }
 
-            lobbyIndex := i + 1
-            finalString := fmt.Sprintf(
-                "%d. Name: %s, Channel template: %s, Capacity: %s",
-                lobbyIndex,
-                channel.Name,
-                template,
-                capacity,
-            )
+        naming := "template"
+        if lobby.Naming.Valid && model.Naming(lobby.Naming.Int32) == model.NamingNumbered {
+            naming = "numbered"
+        }
 
-            log.Debug().Printf("lobby: list command: channels\n%s", finalString)
-            registeredChannels = append(registeredChannels, finalString)
+        var grace int32
+        if lobby.Grace.Valid {
+            grace = lobby.Grace.Int32
         }
+
+        lobbyRooms, _, err := lc.getActiveRooms(lobby.GuildID, lobby.Id)
+        if err != nil {
+            log.Error().Printf("lobby: list command: unable to count active rooms: %v", err)
+        }
+
+        settings := strings.Join([]string{
+            fmt.Sprintf("Rooms: %d/%s", lobbyRooms, getLimitName(lobby.MaxRooms)),
+            fmt.Sprintf("Channel template: %s", template),
+            fmt.Sprintf("Capacity: %s", capacity),
+            fmt.Sprintf("Naming: %s", naming),
+            fmt.Sprintf("Grace period: %ds", grace),
+            fmt.Sprintf("Voice: %s", getVoiceSettingsDescription(lobby)),
+            fmt.Sprintf("Text channel: %s", getTextChannelName(getTextChannelMode(lobby))),
+            fmt.Sprintf("Activity renaming: %t", lobby.Activity.Valid && lobby.Activity.Bool),
+            fmt.Sprintf("Preference overrides: %t", lobby.Overrides.Valid && lobby.Overrides.Bool),
+            fmt.Sprintf("Cooldown: %s", getCooldown(lobby)),
+            fmt.Sprintf("Burst limit: %s per %s", getLimitName(lobby.BurstLimit), burstWindow),
+            fmt.Sprintf("Block spam: %t", lobby.BlockSpam.Valid && lobby.BlockSpam.Bool),
+            fmt.Sprintf("Roles: %s", lc.getRolesDescription(lobby.Id)),
+            fmt.Sprintf("Waiting room: %s", getWaitingName(lobby)),
+            fmt.Sprintf("Permissions: %s", getPermissionSourceName(getPermissionSource(lobby))),
+            fmt.Sprintf("Placement: %s", getPlacementName(getPlacement(lobby))),
+            fmt.Sprintf("Overflow: %s", lc.getOverflowDescription(lobby)),
+        }, "\n")
+
+        // Long role lists are cut, the field value holds 1024 characters at most
+        if len(settings) > maxFieldSize {
+            settings = settings[:strings.LastIndex(settings[:maxFieldSize-2], "\n")] + "\n.."
+        }
+
+        log.Debug().Printf("lobby: list command: channel %s\n%s", channel.Name, settings)
+        fields = append(fields, &discordgo.MessageEmbedField{
+            Name:  fmt.Sprintf("%d. %s", first+index+1, channel.Name),
+            Value: settings,
+        })
     }
 
-    if len(registeredChannels) == 0 {
-        log.Warn().Println("lobby: list command: there are no registered channels")
-        return model.CommandWarning("There are no active lobbies.")
+    log.Info().Printf("lobby: list command: %d lobbies found, page %d of %d", len(lobbies), page, pages)
+
+    guild, err := lc.guildRepository.GetGuild(i.GuildID)
+    if err != nil && !errors.Is(err, sql.ErrNoRows) {
+        log.Error().Printf("lobby: list command: %v", err)
     }
 
-    activeLobbies := strings.Join(registeredChannels, "\n")
-    log.Info().Printf("lobby: list command: active lobbies found:\n%s", activeLobbies)
-    return model.CommandSuccess(
-        fmt.Sprintf("Active Lobbies:\n%s", activeLobbies),
+    _, guildRooms, err := lc.getActiveRooms(i.GuildID, "")
+    if err != nil {
+        log.Error().Printf("lobby: list command: unable to count active rooms: %v", err)
+    }
+
+    description := fmt.Sprintf(
+        "Active Lobbies (rooms: %d/%s), page %d of %d:",
+        guildRooms,
+        getLimitName(guild.MaxRooms),
+        page,
+        pages,
     )
+    if report, ok := lc.getReconcileReport(i.GuildID); ok {
+        description = fmt.Sprintf("Reconciled <t:%d:R>: %s.\n%s", report.finishedAt.Unix(), report, description)
+    }
+
+    response := model.CommandSuccess(description)
+    response.Fields = fields
+    return response
 }
 
 func (lc *Command) handleCommandRemove(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
@@ -575,8 +1031,90 @@ func (lc *Command) handleCommandRemove(s *discordgo.Session, i *discordgo.Intera
         )
     }
 
+    if err := lc.lobbyRolesRepository.DeleteLobbyRoles(channel.ID); err != nil {
+        log.Error().Printf("lobby: remove command: %v", err)
+    }
+
+    if err := lc.lobbyOverwritesRepository.DeleteLobbyOverwrites(channel.ID); err != nil {
+        log.Error().Printf("lobby: remove command: %v", err)
+    }
+
+    if err := lc.lobbyOverflowsRepository.DeleteLobbyOverflows(channel.ID); err != nil {
+        log.Error().Printf("lobby: remove command: %v", err)
+    }
+
     log.Info().Printf("lobby: remove command: lobby %s successfully deleted", channel.Name)
     return model.CommandSuccess(
         fmt.Sprintf("Lobby \"%s\" successfully deleted", channel.Name),
     )
 }
+
+func (lc *Command) handleCommandNaming(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    naming := model.Naming(options[0].Options[1].IntValue())
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: naming command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Naming: sql.NullInt32{
+            Valid: true,
+            Int32: int32(naming),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: naming command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update naming mode for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: naming command: save naming %d for %s[%s]", naming, channel.Name, lobby.Id)
+    if naming == model.NamingNumbered {
+        return model.CommandSuccess(
+            fmt.Sprintf("Rooms of \"%s\" are now numbered.", channel.Name),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("Rooms of \"%s\" are now named after the template.", channel.Name),
+    )
+}
+
+func (lc *Command) handleCommandGrace(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    seconds := options[0].Options[1].IntValue()
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: grace command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Grace: sql.NullInt32{
+            Valid: true,
+            Int32: int32(seconds),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: grace command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update grace period for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: grace command: save grace period %ds for %s[%s]", seconds, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Grace period %ds successfully set for \"%s\".", seconds, channel.Name),
+    )
+}
@@ -0,0 +1,269 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandOverflow       string = "overflow" // Subcommand group lobby overflow
+    commandOverflowAdd    string = "add"      // Subcommand lobby overflow add
+    commandOverflowRemove string = "remove"   // Subcommand lobby overflow remove
+    commandOverflowAuto   string = "auto"     // Subcommand lobby overflow auto
+    optionCategory        string = "category" // Option for commandOverflowAdd, commandOverflowRemove
+)
+
+const maxCategoryChannels = 50 // Discord category holds 50 channels at most
+
+/* ------ COMMANDS ------ */
+
+func getOverflowCommandGroup() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandOverflow,
+        Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+        Description: "Categories used once the lobby category is full.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Name:        commandOverflowAdd,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Add a category to the end of the overflow list of the lobby.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    getCategoryOption("A category to be used once the previous ones are full."),
+                },
+            },
+            {
+                Name:        commandOverflowRemove,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Remove a category from the overflow list of the lobby.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    getCategoryOption("A category to be removed."),
+                },
+            },
+            {
+                Name:        commandOverflowAuto,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Select whether new overflow categories are created once all are full.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionBoolean,
+                        Name:        optionEnabled,
+                        Description: "Whether \"<category> (2)\" is created when needed.",
+                        Required:    true,
+                    },
+                },
+            },
+        },
+    }
+}
+
+func getCategoryOption(description string) *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Type:        discordgo.ApplicationCommandOptionChannel,
+        Name:        optionCategory,
+        Description: description,
+        ChannelTypes: []discordgo.ChannelType{
+            discordgo.ChannelTypeGuildCategory,
+        },
+        Required: true,
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandOverflow(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    subcommand := i.ApplicationCommandData().Options[0].Options[0]
+    channel := subcommand.Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: overflow %s command: %v", subcommand.Name, err)
+        return response
+    }
+
+    if subcommand.Name == commandOverflowAuto {
+        enabled := subcommand.Options[1].BoolValue()
+
+        lobby := model.Lobby{
+            Id:         channel.ID,
+            CategoryID: channel.ParentID,
+            AutoOverflow: sql.NullBool{
+                Valid: true,
+                Bool:  enabled,
+            },
+        }
+
+        if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+            log.Error().Printf("lobby: overflow auto command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+            return model.CommandError(
+                fmt.Sprintf("Unable to update overflow mode for \"%s\"", channel.Name),
+            )
+        }
+
+        log.Info().Printf("lobby: overflow auto command: save auto overflow %t for %s[%s]", enabled, channel.Name, lobby.Id)
+        if enabled {
+            return model.CommandSuccess(
+                fmt.Sprintf("Overflow categories of \"%s\" are now created when needed.", channel.Name),
+            )
+        }
+
+        return model.CommandSuccess(
+            fmt.Sprintf("Overflow categories of \"%s\" are no longer created.", channel.Name),
+        )
+    }
+
+    category := subcommand.Options[1].ChannelValue(s)
+
+    if subcommand.Name == commandOverflowRemove {
+        affectedRows, err := lc.lobbyOverflowsRepository.DeleteLobbyOverflow(channel.ID, category.ID)
+        if err != nil {
+            log.Error().Printf("lobby: overflow remove command: %v", err)
+            return model.CommandError(
+                fmt.Sprintf("Unable to remove \"%s\" from overflow categories of \"%s\".", category.Name, channel.Name),
+            )
+        }
+
+        if affectedRows == 0 {
+            log.Warn().Printf("lobby: overflow remove command: category %s is not an overflow of %s[%s]", category.ID, channel.Name, channel.ID)
+            return model.CommandWarning(
+                fmt.Sprintf("\"%s\" is not an overflow category of \"%s\"!", category.Name, channel.Name),
+            )
+        }
+
+        log.Info().Printf("lobby: overflow remove command: category %s removed from %s[%s]", category.ID, channel.Name, channel.ID)
+        return model.CommandSuccess(
+            fmt.Sprintf("\"%s\" successfully removed from overflow categories of \"%s\".", category.Name, channel.Name),
+        )
+    }
+
+    if category.ID == channel.ParentID {
+        log.Warn().Printf("lobby: overflow add command: category %s is the lobby category", category.ID)
+        return model.CommandWarning("The lobby category cannot be its own overflow!")
+    }
+
+    affectedRows, err := lc.lobbyOverflowsRepository.AddLobbyOverflow(channel.ID, category.ID)
+    if err != nil {
+        log.Error().Printf("lobby: overflow add command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to add \"%s\" to overflow categories of \"%s\".", category.Name, channel.Name),
+        )
+    }
+
+    if affectedRows == 0 {
+        log.Warn().Printf("lobby: overflow add command: category %s is already an overflow of %s[%s]", category.ID, channel.Name, channel.ID)
+        return model.CommandWarning(
+            fmt.Sprintf("\"%s\" is already an overflow category of \"%s\"!", category.Name, channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: overflow add command: category %s added to %s[%s]", category.ID, channel.Name, channel.ID)
+    return model.CommandSuccess(
+        fmt.Sprintf("\"%s\" successfully added to overflow categories of \"%s\".", category.Name, channel.Name),
+    )
+}
+
+/* ------ OVERFLOW ------ */
+
+// getRoomCategory returns the first category of the lobby with space for the new room and its text channel.
+// Overflow categories are used in order once the lobby category is full, a new one is created in auto mode.
+func (lc *Command) getRoomCategory(s *discordgo.Session, guildId string, l model.Lobby) (string, error) {
+    required := 1
+    if getTextChannelMode(l) != model.TextChannelNone {
+        required++
+    }
+
+    if l.CategoryID == "" || hasCategorySpace(s, guildId, l.CategoryID, required) {
+        return l.CategoryID, nil
+    }
+
+    overflows, err := lc.lobbyOverflowsRepository.GetLobbyOverflows(l.Id)
+    if err != nil {
+        return "", fmt.Errorf("db: %w", err)
+    }
+
+    for _, overflow := range overflows {
+        if _, err := s.State.Channel(overflow.CategoryID); err != nil {
+            log.Warn().Printf("voice updates: overflow category %s of lobby %s is gone", overflow.CategoryID, l.Id)
+            continue
+        }
+
+        if hasCategorySpace(s, guildId, overflow.CategoryID, required) {
+            log.Info().Printf("voice updates: lobby %s category is full, use overflow category %s", l.Id, overflow.CategoryID)
+            return overflow.CategoryID, nil
+        }
+    }
+
+    if !l.AutoOverflow.Valid || !l.AutoOverflow.Bool {
+        return "", fmt.Errorf("all categories of lobby %s are full", l.Id)
+    }
+
+    return lc.createOverflowCategory(s, guildId, l, len(overflows)+2)
+}
+
+// createOverflowCategory creates "<category> (n)" with the permissions of the lobby category.
+func (lc *Command) createOverflowCategory(s *discordgo.Session, guildId string, l model.Lobby, number int) (string, error) {
+    category, err := s.State.Channel(l.CategoryID)
+    if err != nil {
+        category, err = s.Channel(l.CategoryID)
+        if err != nil {
+            return "", fmt.Errorf("API: unable to get category %s: %w", l.CategoryID, err)
+        }
+    }
+
+    overwrites, err := discord.CopyOverwrites(s, l.CategoryID)
+    if err != nil {
+        log.Error().Printf("voice updates: unable to copy category permissions: %v", err)
+    }
+
+    name := fmt.Sprintf("%s (%d)", category.Name, number)
+    log.Info().Printf("voice updates: lobby %s categories are full, create overflow category %s", l.Id, name)
+
+    overflow, err := s.GuildChannelCreateComplex(guildId, discordgo.GuildChannelCreateData{
+        Name:                 name,
+        Type:                 discordgo.ChannelTypeGuildCategory,
+        PermissionOverwrites: overwrites,
+    })
+    if err != nil {
+        return "", fmt.Errorf("API: unable to create overflow category %s: %w", name, err)
+    }
+
+    if _, err := lc.lobbyOverflowsRepository.AddLobbyOverflow(l.Id, overflow.ID); err != nil {
+        return "", fmt.Errorf("db: %w", err)
+    }
+
+    return overflow.ID, nil
+}
+
+func (lc *Command) getOverflowDescription(l model.Lobby) string {
+    overflows, err := lc.lobbyOverflowsRepository.GetLobbyOverflows(l.Id)
+    if err != nil {
+        log.Error().Printf("lobby: %v", err)
+        return "unknown"
+    }
+
+    return fmt.Sprintf("%d categories, auto %t", len(overflows), l.AutoOverflow.Valid && l.AutoOverflow.Bool)
+}
+
+func hasCategorySpace(s *discordgo.Session, guildId string, categoryId string, required int) bool {
+    guild, err := s.State.Guild(guildId)
+    if err != nil {
+        log.Error().Printf("voice updates: unable to get guild %s from state: %v", guildId, err)
+        return true
+    }
+
+    var channels int
+    for _, channel := range guild.Channels {
+        if channel.ParentID == categoryId {
+            channels++
+        }
+    }
+
+    return channels+required <= maxCategoryChannels
+}
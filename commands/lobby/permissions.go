@@ -0,0 +1,338 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandPermissions       string = "permissions" // Subcommand group lobby permissions
+    commandPermissionsSource string = "source"      // Subcommand lobby permissions source
+    commandPermissionsSet    string = "set"         // Subcommand lobby permissions set
+    commandPermissionsClear  string = "clear"       // Subcommand lobby permissions clear
+    optionSource             string = "source"      // Option for commandPermissionsSource
+    optionTarget             string = "target"      // Option for commandPermissionsSet
+    optionPermission         string = "permission"  // Option for commandPermissionsSet
+    optionValue              string = "value"       // Option for commandPermissionsSet
+)
+
+// Values of optionValue
+const (
+    permissionInherit int = iota // Neither allowed nor denied, Discord falls back to the role permissions
+    permissionAllow
+    permissionDeny
+)
+
+// ownerGrant is added on top of the permission source for the creator of the room.
+const ownerGrant int64 = discordgo.PermissionManageChannels | discordgo.PermissionVoiceMoveMembers
+
+/* ------ COMMANDS ------ */
+
+func getPermissionsCommandGroup() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandPermissions,
+        Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+        Description: "Permission overwrites of new channels.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Name:        commandPermissionsSource,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Select where new channels take their permission overwrites from.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionInteger,
+                        Name:        optionSource,
+                        Description: "A source of permission overwrites.",
+                        Choices: []*discordgo.ApplicationCommandOptionChoice{
+                            {
+                                Name:  "Category",
+                                Value: model.PermissionSourceCategory,
+                            },
+                            {
+                                Name:  "Lobby channel",
+                                Value: model.PermissionSourceLobby,
+                            },
+                            {
+                                Name:  "Custom",
+                                Value: model.PermissionSourceCustom,
+                            },
+                        },
+                        Required: true,
+                    },
+                },
+            },
+            {
+                Name:        commandPermissionsSet,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Change a custom permission overwrite of new channels.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionMentionable,
+                        Name:        optionTarget,
+                        Description: "A role or a member the overwrite is for.",
+                        Required:    true,
+                    },
+                    {
+                        Type:        discordgo.ApplicationCommandOptionInteger,
+                        Name:        optionPermission,
+                        Description: "A permission to be changed.",
+                        Choices: []*discordgo.ApplicationCommandOptionChoice{
+                            {
+                                Name:  "View channel",
+                                Value: discordgo.PermissionViewChannel,
+                            },
+                            {
+                                Name:  "Connect",
+                                Value: discordgo.PermissionVoiceConnect,
+                            },
+                            {
+                                Name:  "Speak",
+                                Value: discordgo.PermissionVoiceSpeak,
+                            },
+                            {
+                                Name:  "Video",
+                                Value: discordgo.PermissionVoiceStreamVideo,
+                            },
+                            {
+                                Name:  "Use voice activity",
+                                Value: discordgo.PermissionVoiceUseVAD,
+                            },
+                            {
+                                Name:  "Send messages",
+                                Value: discordgo.PermissionSendMessages,
+                            },
+                        },
+                        Required: true,
+                    },
+                    {
+                        Type:        discordgo.ApplicationCommandOptionInteger,
+                        Name:        optionValue,
+                        Description: "Whether the permission is allowed, denied or inherited.",
+                        Choices: []*discordgo.ApplicationCommandOptionChoice{
+                            {
+                                Name:  "Allow",
+                                Value: permissionAllow,
+                            },
+                            {
+                                Name:  "Deny",
+                                Value: permissionDeny,
+                            },
+                            {
+                                Name:  "Inherit",
+                                Value: permissionInherit,
+                            },
+                        },
+                        Required: true,
+                    },
+                },
+            },
+            {
+                Name:        commandPermissionsClear,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Remove all custom permission overwrites of the lobby.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                },
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandPermissions(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    subcommand := i.ApplicationCommandData().Options[0].Options[0]
+    channel := subcommand.Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: permissions %s command: %v", subcommand.Name, err)
+        return response
+    }
+
+    switch subcommand.Name {
+    case commandPermissionsSet:
+        return lc.handleCommandPermissionsSet(i, subcommand, channel)
+    case commandPermissionsClear:
+        if err := lc.lobbyOverwritesRepository.DeleteLobbyOverwrites(channel.ID); err != nil {
+            log.Error().Printf("lobby: permissions clear command: %v", err)
+            return model.CommandError(
+                fmt.Sprintf("Unable to remove custom permissions of \"%s\".", channel.Name),
+            )
+        }
+
+        log.Info().Printf("lobby: permissions clear command: overwrites removed from %s[%s]", channel.Name, channel.ID)
+        return model.CommandSuccess(
+            fmt.Sprintf("Custom permissions of \"%s\" successfully removed.", channel.Name),
+        )
+    }
+
+    source := model.PermissionSource(subcommand.Options[1].IntValue())
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Permissions: sql.NullInt32{
+            Valid: true,
+            Int32: int32(source),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: permissions source command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update permission source for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: permissions source command: save source %d for %s[%s]", source, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("New rooms of \"%s\" now take permissions from the %s.", channel.Name, getPermissionSourceName(source)),
+    )
+}
+
+func (lc *Command) handleCommandPermissionsSet(
+    i *discordgo.InteractionCreate,
+    subcommand *discordgo.ApplicationCommandInteractionDataOption,
+    channel *discordgo.Channel,
+) model.CommandResponse {
+    targetId := subcommand.Options[1].Value.(string)
+    permission := subcommand.Options[2].IntValue()
+    value := int(subcommand.Options[3].IntValue())
+
+    overwrite := model.LobbyOverwrite{
+        LobbyID:  channel.ID,
+        TargetID: targetId,
+        Type:     discordgo.PermissionOverwriteTypeMember,
+    }
+
+    mention := fmt.Sprintf("<@%s>", targetId)
+    if resolved := i.ApplicationCommandData().Resolved; resolved != nil && resolved.Roles[targetId] != nil {
+        overwrite.Type = discordgo.PermissionOverwriteTypeRole
+        mention = resolved.Roles[targetId].Mention()
+    }
+
+    overwrites, err := lc.lobbyOverwritesRepository.GetLobbyOverwrites(channel.ID)
+    if err != nil {
+        log.Error().Printf("lobby: permissions set command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to get custom permissions of \"%s\".", channel.Name),
+        )
+    }
+
+    for _, current := range overwrites {
+        if current.TargetID == targetId {
+            overwrite.Allow = current.Allow
+            overwrite.Deny = current.Deny
+        }
+    }
+
+    overwrite.Allow &^= permission
+    overwrite.Deny &^= permission
+    switch value {
+    case permissionAllow:
+        overwrite.Allow |= permission
+    case permissionDeny:
+        overwrite.Deny |= permission
+    }
+
+    if overwrite.Allow == 0 && overwrite.Deny == 0 {
+        err = lc.lobbyOverwritesRepository.DeleteLobbyOverwrite(channel.ID, targetId)
+    } else {
+        err = lc.lobbyOverwritesRepository.SetLobbyOverwrite(&overwrite)
+    }
+
+    if err != nil {
+        log.Error().Printf("lobby: permissions set command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update permissions of %s in \"%s\".", mention, channel.Name),
+        )
+    }
+
+    log.Info().Printf(
+        "lobby: permissions set command: overwrite of %s set to allow %d, deny %d in %s[%s]",
+        targetId,
+        overwrite.Allow,
+        overwrite.Deny,
+        channel.Name,
+        channel.ID,
+    )
+    return model.CommandSuccess(
+        fmt.Sprintf("Permissions of %s successfully updated in \"%s\".", mention, channel.Name),
+    )
+}
+
+/* ------ PERMISSIONS ------ */
+
+// getRoomOverwrites returns permission overwrites of the new room taken from the lobby source,
+// with the owner grant for the creator on top of them. The category source is the category the room is created in,
+// which is an overflow category once the lobby category is full.
+func (lc *Command) getRoomOverwrites(
+    s *discordgo.Session,
+    l model.Lobby,
+    categoryId string,
+    ownerId string,
+) []*discordgo.PermissionOverwrite {
+    var overwrites []*discordgo.PermissionOverwrite
+
+    switch getPermissionSource(l) {
+    case model.PermissionSourceCategory:
+        if categoryId != "" {
+            copied, err := discord.CopyOverwrites(s, categoryId)
+            if err != nil {
+                log.Error().Printf("voice updates: unable to copy category permissions: %v", err)
+            }
+
+            overwrites = copied
+        }
+    case model.PermissionSourceLobby:
+        copied, err := discord.CopyOverwrites(s, l.Id)
+        if err != nil {
+            log.Error().Printf("voice updates: unable to copy lobby permissions: %v", err)
+        }
+
+        overwrites = copied
+    case model.PermissionSourceCustom:
+        custom, err := lc.lobbyOverwritesRepository.GetLobbyOverwrites(l.Id)
+        if err != nil {
+            log.Error().Printf("voice updates: %v", err)
+        }
+
+        for _, overwrite := range custom {
+            overwrites = append(overwrites, &discordgo.PermissionOverwrite{
+                ID:    overwrite.TargetID,
+                Type:  overwrite.Type,
+                Allow: overwrite.Allow,
+                Deny:  overwrite.Deny,
+            })
+        }
+    }
+
+    return discord.MergeOverwrite(overwrites, ownerId, discordgo.PermissionOverwriteTypeMember, ownerGrant, 0)
+}
+
+func getPermissionSource(l model.Lobby) model.PermissionSource {
+    if !l.Permissions.Valid {
+        return model.PermissionSourceCategory
+    }
+
+    return model.PermissionSource(l.Permissions.Int32)
+}
+
+func getPermissionSourceName(source model.PermissionSource) string {
+    switch source {
+    case model.PermissionSourceLobby:
+        return "lobby channel"
+    case model.PermissionSourceCustom:
+        return "custom set"
+    default:
+        return "category"
+    }
+}
@@ -0,0 +1,234 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "sort"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandPlacement string = "placement" // Subcommand lobby placement
+    optionPlacement  string = "placement" // Option for commandPlacement
+)
+
+/* ------ COMMANDS ------ */
+
+func getPlacementCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandPlacement,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select where new channels are placed in the category.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionPlacement,
+                Description: "A position of new channels.",
+                Choices: []*discordgo.ApplicationCommandOptionChoice{
+                    {
+                        Name:  "Below lobby",
+                        Value: model.PlacementBelow,
+                    },
+                    {
+                        Name:  "Bottom of category",
+                        Value: model.PlacementBottom,
+                    },
+                    {
+                        Name:  "Sorted by index",
+                        Value: model.PlacementIndex,
+                    },
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandPlacement(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    placement := model.Placement(options[0].Options[1].IntValue())
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: placement command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Placement: sql.NullInt32{
+            Valid: true,
+            Int32: int32(placement),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: placement command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update placement for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: placement command: save placement %d for %s[%s]", placement, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Placement \"%s\" successfully set for \"%s\".", getPlacementName(placement), channel.Name),
+    )
+}
+
+/* ------ PLACEMENT ------ */
+
+// placeRoom moves the new room to its position in the category with a single bulk update.
+// Only voice channels whose position has changed are sent to Discord.
+func (lc *Command) placeRoom(s *discordgo.Session, guildId string, l model.Lobby, room *discordgo.Channel) {
+    guild, err := s.State.Guild(guildId)
+    if err != nil {
+        log.Error().Printf("voice updates: unable to get guild %s from state: %v", guildId, err)
+        return
+    }
+
+    var siblings []*discordgo.Channel
+    for _, channel := range guild.Channels {
+        if channel.ParentID != room.ParentID || channel.ID == room.ID || !isVoiceChannel(channel) {
+            continue
+        }
+
+        siblings = append(siblings, channel)
+    }
+
+    sort.SliceStable(siblings, func(a, b int) bool {
+        return isPlacedBefore(siblings[a], siblings[b])
+    })
+
+    order := lc.getRoomOrder(siblings, l, room)
+
+    base := 0
+    if len(siblings) > 0 {
+        base = siblings[0].Position
+    }
+
+    var moved []*discordgo.Channel
+    for index, channel := range order {
+        if position := base + index; channel.Position != position {
+            moved = append(moved, &discordgo.Channel{ID: channel.ID, Position: position})
+        }
+    }
+
+    if len(moved) == 0 {
+        return
+    }
+
+    log.Info().Printf("voice updates: place channel %s, %d channels change position", room.ID, len(moved))
+    if err := s.GuildChannelsReorder(guildId, moved); err != nil {
+        log.Error().Printf("voice updates: unable to place channel %s: %v", room.ID, err)
+    }
+}
+
+// getRoomOrder returns voice channels of the category in the order the lobby placement requires.
+func (lc *Command) getRoomOrder(siblings []*discordgo.Channel, l model.Lobby, room *discordgo.Channel) []*discordgo.Channel {
+    // Overflow categories have no lobby to place the room below
+    placement := getPlacement(l)
+    if placement == model.PlacementBottom || room.ParentID != l.CategoryID {
+        return append(siblings, room)
+    }
+
+    lobbyAt := -1
+    for index, channel := range siblings {
+        if channel.ID == l.Id {
+            lobbyAt = index
+            break
+        }
+    }
+
+    if lobbyAt < 0 {
+        log.Warn().Printf("voice updates: lobby %s is not in its category, place channel %s at the bottom", l.Id, room.ID)
+        return append(siblings, room)
+    }
+
+    if placement == model.PlacementBelow {
+        order := make([]*discordgo.Channel, 0, len(siblings)+1)
+        order = append(order, siblings[:lobbyAt+1]...)
+        order = append(order, room)
+        return append(order, siblings[lobbyAt+1:]...)
+    }
+
+    channels, err := lc.channelRepository.GetChannels(l.GuildID)
+    if err != nil {
+        log.Error().Printf("voice updates: get channels: %v", err)
+        return append(siblings, room)
+    }
+
+    indexes := make(map[string]int)
+    for _, channel := range channels {
+        if channel.ParentID == l.Id {
+            indexes[channel.Id] = channel.Index
+        }
+    }
+
+    rooms := []*discordgo.Channel{room}
+    var others []*discordgo.Channel
+    for _, channel := range siblings {
+        if _, ok := indexes[channel.ID]; ok {
+            rooms = append(rooms, channel)
+        } else {
+            others = append(others, channel)
+        }
+    }
+
+    sort.SliceStable(rooms, func(a, b int) bool {
+        return indexes[rooms[a].ID] < indexes[rooms[b].ID]
+    })
+
+    order := make([]*discordgo.Channel, 0, len(siblings)+1)
+    for _, channel := range others {
+        order = append(order, channel)
+        if channel.ID == l.Id {
+            order = append(order, rooms...)
+        }
+    }
+
+    return order
+}
+
+// isPlacedBefore follows Discord ordering of channels: by position, then by id.
+func isPlacedBefore(a *discordgo.Channel, b *discordgo.Channel) bool {
+    if a.Position != b.Position {
+        return a.Position < b.Position
+    }
+
+    if len(a.ID) != len(b.ID) {
+        return len(a.ID) < len(b.ID)
+    }
+
+    return a.ID < b.ID
+}
+
+func isVoiceChannel(channel *discordgo.Channel) bool {
+    return channel.Type == discordgo.ChannelTypeGuildVoice || channel.Type == discordgo.ChannelTypeGuildStageVoice
+}
+
+func getPlacement(l model.Lobby) model.Placement {
+    if !l.Placement.Valid {
+        return model.PlacementBelow
+    }
+
+    return model.Placement(l.Placement.Int32)
+}
+
+func getPlacementName(placement model.Placement) string {
+    switch placement {
+    case model.PlacementBottom:
+        return "bottom of category"
+    case model.PlacementIndex:
+        return "sorted by index"
+    default:
+        return "below lobby"
+    }
+}
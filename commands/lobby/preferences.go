@@ -0,0 +1,135 @@
+package lobby
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandOverrides string = "overrides" // Subcommand lobby overrides
+)
+
+/* ------ COMMANDS ------ */
+
+func getOverridesCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandOverrides,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Allow members to create channels with their saved room preferences.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionBoolean,
+                Name:        optionEnabled,
+                Description: "Whether saved preferences override the lobby name and capacity.",
+                Required:    true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandOverrides(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    enabled := options[0].Options[1].BoolValue()
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: overrides command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Overrides: sql.NullBool{
+            Valid: true,
+            Bool:  enabled,
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: overrides command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update preference overrides for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: overrides command: save overrides %t for %s[%s]", enabled, channel.Name, lobby.Id)
+    if enabled {
+        return model.CommandSuccess(
+            fmt.Sprintf("Rooms of \"%s\" now follow saved preferences of their creators.", channel.Name),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("Rooms of \"%s\" now ignore saved preferences.", channel.Name),
+    )
+}
+
+/* ------ PREFERENCES ------ */
+
+// getUserPreference returns saved preferences of the room creator if the lobby allows overrides.
+func (lc *Command) getUserPreference(l model.Lobby, guildId string, userId string) (model.UserPreference, bool) {
+    if !l.Overrides.Valid || !l.Overrides.Bool {
+        return model.UserPreference{}, false
+    }
+
+    preference, err := lc.userPreferencesRepository.GetUserPreference(userId, guildId)
+    if err != nil {
+        if !errors.Is(err, sql.ErrNoRows) {
+            log.Error().Printf("voice updates: %v", err)
+        }
+
+        return model.UserPreference{}, false
+    }
+
+    return preference, true
+}
+
+// getRoomPermits returns users permitted by the saved preference and users banned by the owner of the new room.
+// Bans go last, so they win over permits of the same user.
+func (lc *Command) getRoomPermits(
+    guildId string,
+    channel model.Channel,
+    preference model.UserPreference,
+    hasPreference bool,
+) []model.ChannelPermit {
+    var permits []model.ChannelPermit
+    if hasPreference {
+        for _, userId := range preference.Permits {
+            if userId == channel.OwnerID {
+                continue
+            }
+
+            permits = append(permits, model.ChannelPermit{
+                ChannelID: channel.Id,
+                UserID:    userId,
+                Allowed:   true,
+            })
+        }
+    }
+
+    bans, err := lc.roomBansRepository.GetRoomBans(channel.OwnerID, guildId)
+    if err != nil {
+        log.Error().Printf("voice updates: %v", err)
+        return permits
+    }
+
+    for _, ban := range bans {
+        permits = append(permits, model.ChannelPermit{
+            ChannelID: channel.Id,
+            UserID:    ban.UserID,
+            Allowed:   false,
+        })
+    }
+
+    return permits
+}
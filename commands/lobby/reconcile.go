@@ -0,0 +1,193 @@
+package lobby
+
+import (
+    "errors"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/repository"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// reconcileReport keeps track of what a reconciliation pass has repaired.
+type reconcileReport struct {
+    finishedAt      time.Time
+    missingChannels int // Rows removed because the channel no longer exists
+    emptyChannels   int // Channels deleted because nobody is connected
+    pendingChannels int // Empty channels which deletion is resumed
+    restoredMembers int // Members restored from the guild voice states
+    activeChannels  int // Channels with members connected
+}
+
+// reconciles keeps the report of the first successful reconciliation of every guild.
+type reconciles struct {
+    mu      sync.Mutex
+    reports map[string]reconcileReport // Guild id to the report
+}
+
+// HandleGuildCreate rebuilds temporary channels and their members from the voice states of the guild.
+// Stored channels that were deleted in Discord while the bot was offline are removed.
+// Guilds are reconciled once, later GuildCreate events after reconnects and outages are ignored, as voice updates
+// keep the members up to date by then.
+func (lc *Command) HandleGuildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
+    if event.Guild == nil || event.Unavailable {
+        return
+    }
+
+    guild := event.Guild
+    if _, ok := lc.getReconcileReport(guild.ID); ok {
+        log.Debug().Printf("reconcile: guild %s[%s] is already reconciled", guild.Name, guild.ID)
+        return
+    }
+
+    log.Info().Printf("reconcile: verify channels for guild %s[%s]", guild.Name, guild.ID)
+
+    channels, err := lc.channelRepository.GetChannels(guild.ID)
+    if err != nil {
+        log.Error().Printf("reconcile: get channels: %v", err)
+        return
+    }
+
+    guildChannels := make(map[string]bool, len(guild.Channels))
+    for _, channel := range guild.Channels {
+        guildChannels[channel.ID] = true
+    }
+
+    voiceStates := make(map[string][]*discordgo.VoiceState)
+    for _, state := range guild.VoiceStates {
+        voiceStates[state.ChannelID] = append(voiceStates[state.ChannelID], state)
+    }
+
+    report := reconcileReport{}
+    var activeChannels []model.Channel
+    for _, channel := range channels {
+        if !guildChannels[channel.Id] {
+            log.Info().Printf("reconcile: channel %s does not exist anymore, removing..", channel.Id)
+            if err := lc.removeTextChannel(s, channel); err != nil {
+                log.Error().Printf("reconcile: %v", err)
+                continue
+            }
+
+            if err := lc.discardRoom(guild.ID, channel); err != nil {
+                log.Error().Printf("reconcile: %v", err)
+                continue
+            }
+
+            report.missingChannels++
+            continue
+        }
+
+        if len(voiceStates[channel.Id]) > 0 {
+            if channel.DeleteAt > 0 {
+                lc.cancelDeletion(channel)
+            }
+
+            activeChannels = append(activeChannels, channel)
+            continue
+        }
+
+        if remaining := time.Until(time.Unix(channel.DeleteAt, 0)); channel.DeleteAt > 0 && remaining > 0 {
+            lc.scheduleDeletion(s, guild.ID, channel.Id, remaining)
+            report.pendingChannels++
+            continue
+        }
+
+        log.Info().Printf("reconcile: channel %s is empty, deleting..", channel.Id)
+        if err := lc.deleteChannel(s, guild.ID, channel); err != nil {
+            log.Error().Printf("reconcile: %v", err)
+            continue
+        }
+
+        report.emptyChannels++
+    }
+
+    for _, channel := range activeChannels {
+        permits, err := lc.channelPermitsRepository.GetChannelPermits(channel.Id)
+        if err != nil {
+            log.Error().Printf("reconcile: db: unable to get channel permits %s: %v", channel.Id, err)
+        } else if err := commands.ApplyRoomPermissions(s, guild.ID, channel, permits); err != nil {
+            log.Error().Printf("reconcile: unable to restore permissions for channel %s: %v", channel.Id, err)
+        }
+
+        var userIds []string
+        for _, state := range voiceStates[channel.Id] {
+            userIds = append(userIds, state.UserID)
+        }
+
+        if err := syncTextChannel(s, channel, userIds); err != nil {
+            log.Error().Printf("reconcile: unable to restore text channel members for channel %s: %v", channel.Id, err)
+        }
+    }
+
+    // Members are replaced at once, so voice updates never see the guild without its members
+    if err := lc.transactor.InTx(func(tx repository.Tx) error {
+        if err := tx.ChannelMembers().DeleteGuildChannelMembers(guild.ID); err != nil {
+            return err
+        }
+
+        for _, channel := range activeChannels {
+            for _, state := range voiceStates[channel.Id] {
+                if err := tx.ChannelMembers().SetChannelMember(guild.ID, state.UserID, channel.Id); err != nil {
+                    return err
+                }
+            }
+        }
+
+        return nil
+    }); err != nil {
+        log.Error().Printf("reconcile: db: unable to restore channel members for guild %s: %v", guild.ID, err)
+        return
+    }
+
+    for _, channel := range activeChannels {
+        report.restoredMembers += len(voiceStates[channel.Id])
+    }
+    report.activeChannels = len(activeChannels)
+    report.finishedAt = time.Now()
+
+    lc.reconciles.mu.Lock()
+    if lc.reconciles.reports == nil {
+        lc.reconciles.reports = make(map[string]reconcileReport)
+    }
+    lc.reconciles.reports[guild.ID] = report
+    lc.reconciles.mu.Unlock()
+
+    log.Info().Printf("reconcile: guild %s[%s]: %s", guild.Name, guild.ID, report)
+}
+
+func (lc *Command) getReconcileReport(guildId string) (reconcileReport, bool) {
+    lc.reconciles.mu.Lock()
+    defer lc.reconciles.mu.Unlock()
+
+    report, ok := lc.reconciles.reports[guildId]
+    return report, ok
+}
+
+func (r reconcileReport) String() string {
+    return fmt.Sprintf(
+        "removed %d missing channels, deleted %d empty channels, resumed %d deletions, restored %d members in %d channels",
+        r.missingChannels,
+        r.emptyChannels,
+        r.pendingChannels,
+        r.restoredMembers,
+        r.activeChannels,
+    )
+}
+
+func isUnknownChannel(err error) bool {
+    var restErr *discordgo.RESTError
+    if !errors.As(err, &restErr) {
+        return false
+    }
+
+    if restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownChannel {
+        return true
+    }
+
+    return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
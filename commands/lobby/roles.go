@@ -0,0 +1,147 @@
+package lobby
+
+import (
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandRoles       string = "roles"  // Subcommand group lobby roles
+    commandRolesAdd    string = "add"    // Subcommand lobby roles add
+    commandRolesRemove string = "remove" // Subcommand lobby roles remove
+    optionRole         string = "role"   // Option for commandRolesAdd, commandRolesRemove
+    optionAccess       string = "access" // Option for commandRolesAdd
+)
+
+/* ------ COMMANDS ------ */
+
+func getRolesCommandGroup() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandRoles,
+        Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+        Description: "Roles which can create channels.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Name:        commandRolesAdd,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Allow or deny the role to create channels in the lobby.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionRole,
+                        Name:        optionRole,
+                        Description: "A role to be allowed or denied.",
+                        Required:    true,
+                    },
+                    {
+                        Type:        discordgo.ApplicationCommandOptionBoolean,
+                        Name:        optionAccess,
+                        Description: "Whether the role is allowed, members with a denied role can never create channels.",
+                        Required:    true,
+                    },
+                },
+            },
+            {
+                Name:        commandRolesRemove,
+                Type:        discordgo.ApplicationCommandOptionSubCommand,
+                Description: "Remove the role from allowed or denied roles of the lobby.",
+                Options: []*discordgo.ApplicationCommandOption{
+                    getLobbyOption(),
+                    {
+                        Type:        discordgo.ApplicationCommandOptionRole,
+                        Name:        optionRole,
+                        Description: "A role to be removed.",
+                        Required:    true,
+                    },
+                },
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandRoles(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    subcommand := i.ApplicationCommandData().Options[0].Options[0]
+    channel := subcommand.Options[0].ChannelValue(s)
+    role := subcommand.Options[1].RoleValue(s, i.GuildID)
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: roles %s command: %v", subcommand.Name, err)
+        return response
+    }
+
+    if subcommand.Name == commandRolesRemove {
+        affectedRows, err := lc.lobbyRolesRepository.DeleteLobbyRole(channel.ID, role.ID)
+        if err != nil {
+            log.Error().Printf("lobby: roles remove command: %v", err)
+            return model.CommandError(
+                fmt.Sprintf("Unable to remove %s from \"%s\".", role.Mention(), channel.Name),
+            )
+        }
+
+        if affectedRows == 0 {
+            log.Warn().Printf("lobby: roles remove command: role %s is not set for %s[%s]", role.ID, channel.Name, channel.ID)
+            return model.CommandWarning(
+                fmt.Sprintf("%s is neither allowed nor denied in \"%s\"!", role.Mention(), channel.Name),
+            )
+        }
+
+        log.Info().Printf("lobby: roles remove command: role %s removed from %s[%s]", role.ID, channel.Name, channel.ID)
+        return model.CommandSuccess(
+            fmt.Sprintf("%s successfully removed from \"%s\".", role.Mention(), channel.Name),
+        )
+    }
+
+    lobbyRole := model.LobbyRole{
+        LobbyID: channel.ID,
+        RoleID:  role.ID,
+        Allowed: subcommand.Options[2].BoolValue(),
+    }
+
+    if err := lc.lobbyRolesRepository.SetLobbyRole(&lobbyRole); err != nil {
+        log.Error().Printf("lobby: roles add command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to add %s to \"%s\".", role.Mention(), channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: roles add command: role %s allowed %t in %s[%s]", role.ID, lobbyRole.Allowed, channel.Name, channel.ID)
+    if lobbyRole.Allowed {
+        return model.CommandSuccess(
+            fmt.Sprintf("%s can now create rooms in \"%s\".", role.Mention(), channel.Name),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("%s cannot create rooms in \"%s\" anymore.", role.Mention(), channel.Name),
+    )
+}
+
+func (lc *Command) getRolesDescription(lobbyId string) string {
+    roles, err := lc.lobbyRolesRepository.GetLobbyRoles(lobbyId)
+    if err != nil {
+        log.Error().Printf("lobby: %v", err)
+        return "unknown"
+    }
+
+    if len(roles) == 0 {
+        return "everyone"
+    }
+
+    var descriptions []string
+    for _, role := range roles {
+        if role.Allowed {
+            descriptions = append(descriptions, fmt.Sprintf("+<@&%s>", role.RoleID))
+        } else {
+            descriptions = append(descriptions, fmt.Sprintf("-<@&%s>", role.RoleID))
+        }
+    }
+
+    return strings.Join(descriptions, " ")
+}
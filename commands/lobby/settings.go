@@ -0,0 +1,315 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+    "strings"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandVoice        string = "voice"   // Subcommand group lobby voice
+    commandBitrate      string = "bitrate" // Subcommand lobby voice bitrate
+    commandRegion       string = "region"  // Subcommand lobby voice region
+    commandVideoQuality string = "video"   // Subcommand lobby voice video
+    commandNSFW         string = "nsfw"    // Subcommand lobby voice nsfw
+    optionBitrate       string = "bitrate" // Option for commandBitrate
+    optionRegion        string = "region"  // Option for commandRegion
+    optionQuality       string = "quality" // Option for commandVideoQuality
+    optionEnabled       string = "enabled" // Option for commandNSFW
+    regionAutomatic     string = "auto"    // Value of optionRegion to let Discord choose the region
+)
+
+var (
+    minBitrate float64 = 8   // kbps, the lowest bitrate of a voice channel
+    maxBitrate float64 = 384 // kbps, the highest bitrate of a voice channel for boosted servers
+)
+
+/* ------ COMMANDS ------ */
+
+func getVoiceCommandGroup() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandVoice,
+        Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+        Description: "Voice settings of new channels.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getBitrateCommand(),
+            getRegionCommand(),
+            getVideoQualityCommand(),
+            getNSFWCommand(),
+        },
+    }
+}
+
+func getBitrateCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandBitrate,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select new channels' bitrate.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionBitrate,
+                Description: "A new channels' bitrate in kbps, limited by the server boost tier.",
+                MinValue:    &minBitrate,
+                MaxValue:    maxBitrate,
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getRegionCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandRegion,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select new channels' voice region.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionString,
+                Name:        optionRegion,
+                Description: "A new channels' voice region id, e.g. rotterdam, or \"auto\".",
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getVideoQualityCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandVideoQuality,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select new channels' video quality.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionQuality,
+                Description: "A new channels' video quality.",
+                Choices: []*discordgo.ApplicationCommandOptionChoice{
+                    {
+                        Name:  "Auto",
+                        Value: discord.VideoQualityAuto,
+                    },
+                    {
+                        Name:  "720p",
+                        Value: discord.VideoQualityFull,
+                    },
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+func getNSFWCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandNSFW,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Mark new channels as age-restricted.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionBoolean,
+                Name:        optionEnabled,
+                Description: "Whether new channels are age-restricted.",
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getLobbyOption() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Type:        discordgo.ApplicationCommandOptionChannel,
+        Name:        optionLobby,
+        Description: "A lobby to be configured.",
+        ChannelTypes: []discordgo.ChannelType{
+            discordgo.ChannelTypeGuildVoice,
+        },
+        Required: true,
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandVoice(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    subcommand := i.ApplicationCommandData().Options[0].Options[0]
+    channel := subcommand.Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: voice %s command: %v", subcommand.Name, err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+    }
+
+    var setting string
+    switch subcommand.Name {
+    case commandBitrate:
+        bitrate := int(subcommand.Options[1].IntValue()) * 1000
+        tier := getPremiumTier(s, i.GuildID)
+
+        if maxBitrate := discord.MaxBitrate(tier); bitrate > maxBitrate {
+            log.Warn().Printf("lobby: voice bitrate command: bitrate %d exceeds %d for tier %d", bitrate, maxBitrate, tier)
+            return model.CommandWarning(
+                fmt.Sprintf(
+                    "Bitrate %d kbps does not fit this server!\nServer boost level %d allows up to %d kbps.",
+                    bitrate/1000,
+                    tier,
+                    maxBitrate/1000,
+                ),
+            )
+        }
+
+        lobby.Bitrate = sql.NullInt32{Valid: true, Int32: int32(bitrate)}
+        setting = fmt.Sprintf("Bitrate %d kbps", bitrate/1000)
+    case commandRegion:
+        region := strings.ToLower(strings.TrimSpace(subcommand.Options[1].StringValue()))
+
+        if region != regionAutomatic {
+            if response, err := validateRegion(s, region); err != nil {
+                log.Warn().Printf("lobby: voice region command: %v", err)
+                return response
+            }
+        } else {
+            region = ""
+        }
+
+        lobby.RTCRegion = sql.NullString{Valid: true, String: region}
+        setting = fmt.Sprintf("Voice region \"%s\"", subcommand.Options[1].StringValue())
+    case commandVideoQuality:
+        quality := subcommand.Options[1].IntValue()
+
+        lobby.VideoQuality = sql.NullInt32{Valid: true, Int32: int32(quality)}
+        setting = fmt.Sprintf("Video quality \"%s\"", getVideoQualityName(int(quality)))
+    case commandNSFW:
+        enabled := subcommand.Options[1].BoolValue()
+
+        lobby.NSFW = sql.NullBool{Valid: true, Bool: enabled}
+        setting = fmt.Sprintf("Age restriction \"%t\"", enabled)
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: voice %s command: unable to update lobby %s[%s]: %v", subcommand.Name, channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update voice settings for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: voice %s command: save %s for %s[%s]", subcommand.Name, setting, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("%s successfully set for \"%s\".", setting, channel.Name),
+    )
+}
+
+func validateRegion(s *discordgo.Session, region string) (model.CommandResponse, error) {
+    regions, err := s.VoiceRegions()
+    if err != nil {
+        return model.CommandError("Unable to get Discord voice regions!"),
+            fmt.Errorf("API: unable to get voice regions: %w", err)
+    }
+
+    var available []string
+    for _, r := range regions {
+        if r.ID == region {
+            return model.CommandResponse{}, nil
+        }
+
+        available = append(available, r.ID)
+    }
+
+    return model.CommandWarning(
+            fmt.Sprintf(
+                "\"%s\" is not a voice region!\nAvailable regions: %s, %s.",
+                region,
+                regionAutomatic,
+                strings.Join(available, ", "),
+            ),
+        ),
+        fmt.Errorf("%s is not a voice region", region)
+}
+
+// getVoiceSettings returns voice settings of the lobby that fit the current boost tier of the guild.
+func getVoiceSettings(s *discordgo.Session, guildId string, l model.Lobby) discord.VoiceChannelCreateData {
+    var data discord.VoiceChannelCreateData
+
+    if l.Bitrate.Valid && int(l.Bitrate.Int32) >= discord.MinBitrate {
+        data.Bitrate = int(l.Bitrate.Int32)
+
+        if maxBitrate := discord.MaxBitrate(getPremiumTier(s, guildId)); data.Bitrate > maxBitrate {
+            log.Warn().Printf("voice updates: lobby %s bitrate %d exceeds %d, lowering", l.Id, data.Bitrate, maxBitrate)
+            data.Bitrate = maxBitrate
+        }
+    }
+
+    if l.RTCRegion.Valid {
+        data.RTCRegion = l.RTCRegion.String
+    }
+
+    if l.VideoQuality.Valid {
+        data.VideoQualityMode = int(l.VideoQuality.Int32)
+    }
+
+    if l.NSFW.Valid {
+        data.NSFW = l.NSFW.Bool
+    }
+
+    return data
+}
+
+func getVoiceSettingsDescription(l model.Lobby) string {
+    bitrate := "default"
+    if l.Bitrate.Valid && int(l.Bitrate.Int32) >= discord.MinBitrate {
+        bitrate = fmt.Sprintf("%d kbps", l.Bitrate.Int32/1000)
+    }
+
+    region := regionAutomatic
+    if l.RTCRegion.Valid && l.RTCRegion.String != "" {
+        region = l.RTCRegion.String
+    }
+
+    quality := getVideoQualityName(discord.VideoQualityAuto)
+    if l.VideoQuality.Valid {
+        quality = getVideoQualityName(int(l.VideoQuality.Int32))
+    }
+
+    return fmt.Sprintf(
+        "bitrate %s, region %s, video %s, nsfw %t",
+        bitrate,
+        region,
+        quality,
+        l.NSFW.Valid && l.NSFW.Bool,
+    )
+}
+
+func getPremiumTier(s *discordgo.Session, guildId string) discordgo.PremiumTier {
+    guild, err := s.State.Guild(guildId)
+    if err != nil {
+        guild, err = s.Guild(guildId)
+        if err != nil {
+            log.Error().Printf("lobby: unable to get guild %s: %v", guildId, err)
+            return discordgo.PremiumTierNone
+        }
+    }
+
+    return guild.PremiumTier
+}
+
+func getVideoQualityName(quality int) string {
+    if quality == discord.VideoQualityFull {
+        return "720p"
+    }
+
+    return "Auto"
+}
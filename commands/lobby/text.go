@@ -0,0 +1,260 @@
+package lobby
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandText string = "text" // Subcommand lobby text
+)
+
+/* ------ COMMANDS ------ */
+
+func getTextCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandText,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Select whether new channels get a private text channel.",
+        Options: []*discordgo.ApplicationCommandOption{
+            getLobbyOption(),
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionMode,
+                Description: "What happens to the text channel once the room is deleted.",
+                Choices: []*discordgo.ApplicationCommandOptionChoice{
+                    {
+                        Name:  "Off",
+                        Value: model.TextChannelNone,
+                    },
+                    {
+                        Name:  "Delete with room",
+                        Value: model.TextChannelDelete,
+                    },
+                    {
+                        Name:  "Archive with room",
+                        Value: model.TextChannelArchive,
+                    },
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (lc *Command) handleCommandText(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].ChannelValue(s)
+    mode := model.TextChannel(options[0].Options[1].IntValue())
+
+    if response, err := commands.HasLobby(lc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("lobby: text command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        TextChannel: sql.NullInt32{
+            Valid: true,
+            Int32: int32(mode),
+        },
+    }
+
+    if err := lc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf("lobby: text command: unable to update lobby %s[%s]: %v", channel.Name, channel.ID, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to update text channel mode for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("lobby: text command: save text channel mode %d for %s[%s]", mode, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Text channel mode \"%s\" successfully set for \"%s\".", getTextChannelName(mode), channel.Name),
+    )
+}
+
+/* ------ TEXT CHANNELS ------ */
+
+// createTextChannel creates a text channel next to the room, visible only to the bot and the room owner.
+// Returns an empty id if the lobby has no companion text channels.
+func createTextChannel(
+    s *discordgo.Session,
+    guildId string,
+    l model.Lobby,
+    categoryId string,
+    name string,
+    ownerId string,
+) (string, error) {
+    if getTextChannelMode(l) == model.TextChannelNone {
+        return "", nil
+    }
+
+    textChannel, err := s.GuildChannelCreateComplex(guildId, discordgo.GuildChannelCreateData{
+        Name:     name,
+        Type:     discordgo.ChannelTypeGuildText,
+        ParentID: categoryId,
+        NSFW:     l.NSFW.Valid && l.NSFW.Bool,
+        PermissionOverwrites: []*discordgo.PermissionOverwrite{
+            {
+                // @everyone role has the same id as the guild
+                ID:   guildId,
+                Type: discordgo.PermissionOverwriteTypeRole,
+                Deny: discordgo.PermissionViewChannel,
+            },
+            {
+                ID:    s.State.User.ID,
+                Type:  discordgo.PermissionOverwriteTypeMember,
+                Allow: discordgo.PermissionViewChannel,
+            },
+            {
+                ID:    ownerId,
+                Type:  discordgo.PermissionOverwriteTypeMember,
+                Allow: discordgo.PermissionViewChannel,
+            },
+        },
+    })
+    if err != nil {
+        return "", fmt.Errorf("API: unable to create text channel %s: %w", name, err)
+    }
+
+    return textChannel.ID, nil
+}
+
+// grantTextChannel lets the room member see its text channel.
+func grantTextChannel(s *discordgo.Session, channel model.Channel, userId string) {
+    if channel.TextID == "" {
+        return
+    }
+
+    if err := discord.EditOverwrite(
+        s,
+        channel.TextID,
+        userId,
+        discordgo.PermissionOverwriteTypeMember,
+        discordgo.PermissionViewChannel,
+        0,
+    ); err != nil {
+        log.Error().Printf("voice updates: API: unable to grant text channel %s to %s: %v", channel.TextID, userId, err)
+    }
+}
+
+// revokeTextChannel hides the text channel from the user who left the room.
+func revokeTextChannel(s *discordgo.Session, channel model.Channel, userId string) {
+    if channel.TextID == "" {
+        return
+    }
+
+    if err := discord.ClearOverwrite(
+        s,
+        channel.TextID,
+        userId,
+        discordgo.PermissionOverwriteTypeMember,
+        discordgo.PermissionViewChannel,
+    ); err != nil && !isUnknownChannel(err) {
+        log.Error().Printf("voice updates: API: unable to revoke text channel %s from %s: %v", channel.TextID, userId, err)
+    }
+}
+
+// syncTextChannel makes the text channel visible exactly to the given room members.
+func syncTextChannel(s *discordgo.Session, channel model.Channel, userIds []string) error {
+    if channel.TextID == "" {
+        return nil
+    }
+
+    textChannel, err := s.Channel(channel.TextID)
+    if err != nil {
+        return fmt.Errorf("API: unable to get text channel %s: %w", channel.TextID, err)
+    }
+
+    members := make(map[string]bool, len(userIds))
+    for _, userId := range userIds {
+        members[userId] = true
+    }
+
+    for _, overwrite := range textChannel.PermissionOverwrites {
+        if overwrite.Type != discordgo.PermissionOverwriteTypeMember || overwrite.ID == s.State.User.ID {
+            continue
+        }
+
+        if !members[overwrite.ID] {
+            revokeTextChannel(s, channel, overwrite.ID)
+        }
+    }
+
+    for _, userId := range userIds {
+        grantTextChannel(s, channel, userId)
+    }
+
+    return nil
+}
+
+// removeTextChannel deletes or archives the text channel of the room, depending on its lobby.
+// Text channels of removed lobbies are deleted.
+func (lc *Command) removeTextChannel(s *discordgo.Session, channel model.Channel) error {
+    if channel.TextID == "" {
+        return nil
+    }
+
+    textChannel, err := s.Channel(channel.TextID)
+    if err != nil {
+        if isUnknownChannel(err) {
+            return nil
+        }
+
+        return fmt.Errorf("API: unable to get text channel %s: %w", channel.TextID, err)
+    }
+
+    mode := model.TextChannelDelete
+    if l, err := lc.lobbyRepository.GetLobby(channel.ParentID, textChannel.GuildID); err == nil {
+        mode = getTextChannelMode(l)
+    }
+
+    if mode != model.TextChannelArchive {
+        if _, err := s.ChannelDelete(textChannel.ID); err != nil && !isUnknownChannel(err) {
+            return fmt.Errorf("API: unable to delete text channel %s: %w", textChannel.ID, err)
+        }
+
+        return nil
+    }
+
+    log.Info().Printf("voice updates: archive text channel %s", textChannel.ID)
+    for _, overwrite := range textChannel.PermissionOverwrites {
+        if overwrite.Type != discordgo.PermissionOverwriteTypeMember || overwrite.ID == s.State.User.ID {
+            continue
+        }
+
+        if err := s.ChannelPermissionDelete(textChannel.ID, overwrite.ID); err != nil {
+            return fmt.Errorf("API: unable to archive text channel %s: %w", textChannel.ID, err)
+        }
+    }
+
+    return nil
+}
+
+func getTextChannelMode(l model.Lobby) model.TextChannel {
+    if !l.TextChannel.Valid {
+        return model.TextChannelNone
+    }
+
+    return model.TextChannel(l.TextChannel.Int32)
+}
+
+func getTextChannelName(mode model.TextChannel) string {
+    switch mode {
+    case model.TextChannelDelete:
+        return "delete with room"
+    case model.TextChannelArchive:
+        return "archive with room"
+    default:
+        return "off"
+    }
+}
@@ -0,0 +1,163 @@
+package lobby
+
+import (
+    "hometown-bot/model"
+    "hometown-bot/util/discord"
+    "time"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandExport string = "export" // Subcommand lobby export
+    commandImport string = "import" // Subcommand lobby import
+    optionFormat  string = "format" // Option for commandExport
+    optionFile    string = "file"   // Option for commandImport
+    formatJSON    string = "json"   // Value of optionFormat
+    formatYAML    string = "yaml"   // Value of optionFormat
+)
+
+// Custom ids of import components are "import:<action>:<key>"
+const (
+    importPrefix  string = "import"  // Prefix of import components
+    importConfirm string = "confirm" // Caller applies the import
+    importCancel  string = "cancel"  // Caller drops the import
+)
+
+const (
+    exportVersion  = 1                // Version of the exported document, bumped on incompatible changes
+    importTimeout  = 10 * time.Minute // Time the caller has to confirm an import
+    maxImportSize  = 1 << 20          // Bytes, larger attachments are rejected before the download
+    maxPreviewSize = 4000             // Embed description holds 4096 characters
+    maxIssues      = 10               // Validation issues listed to the caller
+)
+
+var maxCapacity float64 = 99 // Discord voice channel holds 99 users at most, 0 is unlimited
+
+// Names of the enum settings in exported documents
+var (
+    namingNames = map[string]int32{
+        "template": int32(model.NamingTemplate),
+        "numbered": int32(model.NamingNumbered),
+    }
+    videoNames = map[string]int32{
+        "auto": int32(discord.VideoQualityAuto),
+        "720p": int32(discord.VideoQualityFull),
+    }
+    textNames = map[string]int32{
+        "none":    int32(model.TextChannelNone),
+        "delete":  int32(model.TextChannelDelete),
+        "archive": int32(model.TextChannelArchive),
+    }
+    permissionNames = map[string]int32{
+        "category": int32(model.PermissionSourceCategory),
+        "lobby":    int32(model.PermissionSourceLobby),
+        "custom":   int32(model.PermissionSourceCustom),
+    }
+    placementNames = map[string]int32{
+        "below":  int32(model.PlacementBelow),
+        "bottom": int32(model.PlacementBottom),
+        "index":  int32(model.PlacementIndex),
+    }
+    overwriteNames = map[string]int32{
+        "role":   int32(discordgo.PermissionOverwriteTypeRole),
+        "member": int32(discordgo.PermissionOverwriteTypeMember),
+    }
+)
+
+// lobbyExport describes all lobbies of a guild. Settings missing from the document have their default values.
+type lobbyExport struct {
+    Version  int           `json:"version" yaml:"version"`
+    MaxRooms *int32        `json:"max_rooms,omitempty" yaml:"max_rooms,omitempty"` // Room limit of the guild
+    Lobbies  []lobbyConfig `json:"lobbies" yaml:"lobbies"`
+}
+
+type lobbyConfig struct {
+    ID           string            `json:"id" yaml:"id"`
+    Name         string            `json:"name" yaml:"name"` // Used to find the lobby channel in another guild
+    Template     *string           `json:"template,omitempty" yaml:"template,omitempty"`
+    Capacity     *int32            `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+    Naming       *string           `json:"naming,omitempty" yaml:"naming,omitempty"`
+    Grace        *int32            `json:"grace,omitempty" yaml:"grace,omitempty"`
+    Bitrate      *int32            `json:"bitrate,omitempty" yaml:"bitrate,omitempty"` // kbps
+    Region       *string           `json:"region,omitempty" yaml:"region,omitempty"`
+    Video        *string           `json:"video,omitempty" yaml:"video,omitempty"`
+    NSFW         *bool             `json:"nsfw,omitempty" yaml:"nsfw,omitempty"`
+    Text         *string           `json:"text,omitempty" yaml:"text,omitempty"`
+    Activity     *bool             `json:"activity,omitempty" yaml:"activity,omitempty"`
+    Overrides    *bool             `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+    Cooldown     *int32            `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+    BurstLimit   *int32            `json:"burst_limit,omitempty" yaml:"burst_limit,omitempty"`
+    BlockSpam    *bool             `json:"block_spam,omitempty" yaml:"block_spam,omitempty"`
+    MaxRooms     *int32            `json:"max_rooms,omitempty" yaml:"max_rooms,omitempty"`
+    Waiting      *reference        `json:"waiting,omitempty" yaml:"waiting,omitempty"`
+    Permissions  *string           `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+    Placement    *string           `json:"placement,omitempty" yaml:"placement,omitempty"`
+    AutoOverflow *bool             `json:"auto_overflow,omitempty" yaml:"auto_overflow,omitempty"`
+    Roles        []roleConfig      `json:"roles,omitempty" yaml:"roles,omitempty"`
+    Overwrites   []overwriteConfig `json:"overwrites,omitempty" yaml:"overwrites,omitempty"`
+    Overflows    []reference       `json:"overflows,omitempty" yaml:"overflows,omitempty"` // Categories in order of use
+}
+
+// reference points to a channel or a role by its id, or by its name when moving to another guild.
+type reference struct {
+    ID   string `json:"id" yaml:"id"`
+    Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+type roleConfig struct {
+    ID      string `json:"id" yaml:"id"`
+    Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+    Allowed bool   `json:"allowed" yaml:"allowed"`
+}
+
+type overwriteConfig struct {
+    ID    string `json:"id" yaml:"id"`
+    Name  string `json:"name,omitempty" yaml:"name,omitempty"` // Roles only, members keep their id in every guild
+    Type  string `json:"type" yaml:"type"`
+    Allow int64  `json:"allow" yaml:"allow"`
+    Deny  int64  `json:"deny" yaml:"deny"`
+}
+
+/* ------ COMMANDS ------ */
+
+func getExportCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandExport,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Export all lobbies and their settings as a file.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionString,
+                Name:        optionFormat,
+                Description: "A file format, JSON by default.",
+                Choices: []*discordgo.ApplicationCommandOptionChoice{
+                    {
+                        Name:  "JSON",
+                        Value: formatJSON,
+                    },
+                    {
+                        Name:  "YAML",
+                        Value: formatYAML,
+                    },
+                },
+            },
+        },
+    }
+}
+
+func getImportCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandImport,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Import lobbies and their settings from an exported file.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionAttachment,
+                Name:        optionFile,
+                Description: "A JSON or YAML file made by /lobby export.",
+                Required:    true,
+            },
+        },
+    }
+}
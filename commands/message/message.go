@@ -70,6 +70,10 @@ func getRegisterCommand() *discordgo.ApplicationCommandOption {
 }
 
 func (mc *Command) HandleSlashCommands(discord *discordgo.Session, interaction *discordgo.InteractionCreate) {
+    if interaction.Type != discordgo.InteractionApplicationCommand {
+        return
+    }
+
     if handler, ok := mc.commandHandlers[interaction.ApplicationCommandData().Name]; ok {
         handler(discord, interaction)
     }
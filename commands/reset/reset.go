@@ -16,7 +16,25 @@ const (
     commandGroup    string = "lobby"    // Command group
     commandCapacity string = "capacity" // Subcommand channel capacity
     commandName     string = "name"     // Subcommand channel name
-    optionLobby     string = "lobby"    // Option for [commandCapacity], [commandName]
+    commandNaming   string = "naming"   // Subcommand channel naming mode
+    commandGrace    string = "grace"    // Subcommand channel grace period
+    commandBitrate  string = "bitrate"  // Subcommand channel bitrate
+    commandRegion   string = "region"   // Subcommand channel voice region
+    commandVideo    string = "video"    // Subcommand channel video quality
+    commandNSFW     string = "nsfw"     // Subcommand channel age restriction
+    commandText     string = "text"     // Subcommand channel companion text channel
+    commandActivity string = "activity" // Subcommand channel activity renaming
+    optionLobby     string = "lobby"    // Option for every subcommand
+)
+
+const (
+    commandOverrides   string = "overrides"   // Subcommand channel preference overrides
+    commandCooldown    string = "cooldown"    // Subcommand channel creation cooldown
+    commandLimit       string = "limit"       // Subcommand lobby active rooms limit
+    commandWaiting     string = "waiting"     // Subcommand lobby waiting room
+    commandPermissions string = "permissions" // Subcommand lobby permission source
+    commandPlacement   string = "placement"   // Subcommand lobby room placement
+    commandOverflow    string = "overflow"    // Subcommand lobby overflow categories creation
 )
 
 var (
@@ -42,6 +60,10 @@ func New(channelRepository repository.ChannelRepository, lobbyRepository reposit
 }
 
 func (rc *Command) HandleSlashCommands(discord *discordgo.Session, interaction *discordgo.InteractionCreate) {
+    if interaction.Type != discordgo.InteractionApplicationCommand {
+        return
+    }
+
     if handler, ok := rc.commandHandlers[interaction.ApplicationCommandData().Name]; ok {
         handler(discord, interaction)
     }
@@ -71,6 +93,21 @@ func getLobbyCommandGroup() *discordgo.ApplicationCommandOption {
         Options: []*discordgo.ApplicationCommandOption{
             getCapacityCommand(),
             getNameCommand(),
+            getNamingCommand(),
+            getGraceCommand(),
+            getSettingCommand(commandBitrate, "Set new room bitrate to default."),
+            getSettingCommand(commandRegion, "Set new room voice region to automatic."),
+            getSettingCommand(commandVideo, "Set new room video quality to automatic."),
+            getSettingCommand(commandNSFW, "Remove age restriction from new rooms."),
+            getSettingCommand(commandText, "Stop creating text channels for new rooms."),
+            getSettingCommand(commandActivity, "Stop renaming rooms after the game being played."),
+            getSettingCommand(commandOverrides, "Stop applying saved preferences to new rooms."),
+            getSettingCommand(commandCooldown, "Remove room creation cooldown and burst limit, stop blocking spammers."),
+            getSettingCommand(commandLimit, "Remove the limit of active rooms."),
+            getSettingCommand(commandWaiting, "Remove the waiting room."),
+            getSettingCommand(commandPermissions, "Take permissions of new rooms from the category."),
+            getSettingCommand(commandPlacement, "Place new rooms directly below the lobby."),
+            getSettingCommand(commandOverflow, "Stop creating overflow categories."),
         },
     }
 }
@@ -113,6 +150,63 @@ func getNameCommand() *discordgo.ApplicationCommandOption {
     }
 }
 
+func getNamingCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandNaming,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Set new room naming mode to default \"template\".",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionLobby,
+                Description: "A lobby to be configured.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+func getGraceCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandGrace,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Delete empty rooms immediately.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionLobby,
+                Description: "A lobby to be configured.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
+func getSettingCommand(name string, description string) *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        name,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: description,
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionChannel,
+                Name:        optionLobby,
+                Description: "A lobby to be configured.",
+                ChannelTypes: []discordgo.ChannelType{
+                    discordgo.ChannelTypeGuildVoice,
+                },
+                Required: true,
+            },
+        },
+    }
+}
+
 /* ------ INTERACTIONS ------ */
 
 func (rc *Command) createCommandHandlers() map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -131,6 +225,64 @@ func (rc *Command) createCommandHandlers() map[string]func(s *discordgo.Session,
                 commandResponse = rc.handleCommandCapacity(s, i)
             case commandName:
                 commandResponse = rc.handleCommandName(s, i)
+            case commandNaming:
+                commandResponse = rc.handleCommandNaming(s, i)
+            case commandGrace:
+                commandResponse = rc.handleCommandGrace(s, i)
+            case commandBitrate:
+                commandResponse = rc.handleCommandSetting(s, i, "bitrate", func(lobby *model.Lobby) {
+                    lobby.Bitrate = sql.NullInt32{Valid: true, Int32: 0}
+                })
+            case commandRegion:
+                commandResponse = rc.handleCommandSetting(s, i, "voice region", func(lobby *model.Lobby) {
+                    lobby.RTCRegion = sql.NullString{Valid: true, String: ""}
+                })
+            case commandVideo:
+                commandResponse = rc.handleCommandSetting(s, i, "video quality", func(lobby *model.Lobby) {
+                    lobby.VideoQuality = sql.NullInt32{Valid: true, Int32: 0}
+                })
+            case commandNSFW:
+                commandResponse = rc.handleCommandSetting(s, i, "age restriction", func(lobby *model.Lobby) {
+                    lobby.NSFW = sql.NullBool{Valid: true, Bool: false}
+                })
+            case commandText:
+                commandResponse = rc.handleCommandSetting(s, i, "text channel mode", func(lobby *model.Lobby) {
+                    lobby.TextChannel = sql.NullInt32{Valid: true, Int32: int32(model.TextChannelNone)}
+                })
+            case commandActivity:
+                commandResponse = rc.handleCommandSetting(s, i, "activity renaming", func(lobby *model.Lobby) {
+                    lobby.Activity = sql.NullBool{Valid: true, Bool: false}
+                })
+            case commandOverrides:
+                commandResponse = rc.handleCommandSetting(s, i, "preference overrides", func(lobby *model.Lobby) {
+                    lobby.Overrides = sql.NullBool{Valid: true, Bool: false}
+                })
+            case commandCooldown:
+                commandResponse = rc.handleCommandSetting(s, i, "cooldown", func(lobby *model.Lobby) {
+                    lobby.Cooldown = sql.NullInt32{Valid: true, Int32: 0}
+                    lobby.BurstLimit = sql.NullInt32{Valid: true, Int32: 0}
+                    lobby.BlockSpam = sql.NullBool{Valid: true, Bool: false}
+                })
+            case commandLimit:
+                commandResponse = rc.handleCommandSetting(s, i, "room limit", func(lobby *model.Lobby) {
+                    lobby.MaxRooms = sql.NullInt32{Valid: true, Int32: 0}
+                })
+            case commandWaiting:
+                commandResponse = rc.handleCommandSetting(s, i, "waiting room", func(lobby *model.Lobby) {
+                    lobby.WaitingID = sql.NullString{Valid: true, String: ""}
+                })
+            case commandPermissions:
+                commandResponse = rc.handleCommandSetting(s, i, "permission source", func(lobby *model.Lobby) {
+                    lobby.Permissions = sql.NullInt32{Valid: true, Int32: int32(model.PermissionSourceCategory)}
+                })
+            case commandPlacement:
+                commandResponse = rc.handleCommandSetting(s, i, "placement", func(lobby *model.Lobby) {
+                    lobby.Placement = sql.NullInt32{Valid: true, Int32: int32(model.PlacementBelow)}
+                })
+            case commandOverflow:
+                commandResponse = rc.handleCommandSetting(s, i, "overflow mode", func(lobby *model.Lobby) {
+                    lobby.AutoOverflow = sql.NullBool{Valid: true, Bool: false}
+                })
             }
 
             log.Info().Printf("reset: sending interaction response for %s:%s", slashCommand, subcommandCommand)
@@ -222,3 +374,119 @@ func (rc *Command) handleCommandName(s *discordgo.Session, i *discordgo.Interact
         fmt.Sprintf("Name successfully reset for \"%s\".", channel.Name),
     )
 }
+
+func (rc *Command) handleCommandNaming(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(rc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("reset: naming command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Naming: sql.NullInt32{
+            Valid: true,
+            Int32: int32(model.NamingTemplate),
+        },
+    }
+
+    if err := rc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf(
+            "reset: naming command: unable to reset naming for lobby %s[%s]: %v",
+            channel.Name,
+            channel.ID,
+            err,
+        )
+
+        return model.CommandError(
+            fmt.Sprintf("Unable to reset naming for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("reset: naming command: naming reset for %s[%s]", channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Naming successfully reset for \"%s\".", channel.Name),
+    )
+}
+
+func (rc *Command) handleCommandGrace(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(rc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("reset: grace command: %v", err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+        Grace: sql.NullInt32{
+            Valid: true,
+            Int32: 0,
+        },
+    }
+
+    if err := rc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf(
+            "reset: grace command: unable to reset grace period for lobby %s[%s]: %v",
+            channel.Name,
+            channel.ID,
+            err,
+        )
+
+        return model.CommandError(
+            fmt.Sprintf("Unable to reset grace period for \"%s\"", channel.Name),
+        )
+    }
+
+    log.Info().Printf("reset: grace command: grace period reset for %s[%s]", channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Grace period successfully reset for \"%s\".", channel.Name),
+    )
+}
+
+// handleCommandSetting resets a lobby setting, reset sets the default value of the setting to the lobby.
+func (rc *Command) handleCommandSetting(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    setting string,
+    reset func(lobby *model.Lobby),
+) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    channel := options[0].Options[0].Options[0].ChannelValue(s)
+
+    if response, err := commands.HasLobby(rc.lobbyRepository, channel, i.GuildID); err != nil {
+        log.Warn().Printf("reset: %s command: %v", setting, err)
+        return response
+    }
+
+    lobby := model.Lobby{
+        Id:         channel.ID,
+        CategoryID: channel.ParentID,
+    }
+    reset(&lobby)
+
+    if err := rc.lobbyRepository.UpsertLobby(&lobby); err != nil {
+        log.Error().Printf(
+            "reset: %s command: unable to reset %s for lobby %s[%s]: %v",
+            setting,
+            setting,
+            channel.Name,
+            channel.ID,
+            err,
+        )
+
+        return model.CommandError(
+            fmt.Sprintf("Unable to reset %s for \"%s\"", setting, channel.Name),
+        )
+    }
+
+    log.Info().Printf("reset: %s command: %s reset for %s[%s]", setting, setting, channel.Name, lobby.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("Successfully reset %s for \"%s\".", setting, channel.Name),
+    )
+}
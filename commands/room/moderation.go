@@ -0,0 +1,197 @@
+package room
+
+import (
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    commandKick  string = "kick"  // Subcommand room kick
+    commandBan   string = "ban"   // Subcommand room ban
+    commandUnban string = "unban" // Subcommand room unban
+)
+
+/* ------ COMMANDS ------ */
+
+func getKickCommand() *discordgo.ApplicationCommandOption {
+    return getModerationCommand(commandKick, "Disconnect a user from your room.", "A user to be disconnected.")
+}
+
+func getBanCommand() *discordgo.ApplicationCommandOption {
+    return getModerationCommand(commandBan, "Disconnect a user and keep them out of your rooms.", "A user to be banned.")
+}
+
+func getUnbanCommand() *discordgo.ApplicationCommandOption {
+    return getModerationCommand(commandUnban, "Let a banned user join your rooms again.", "A user to be unbanned.")
+}
+
+func getModerationCommand(name string, description string, userDescription string) *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        name,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: description,
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionUser,
+                Name:        optionUser,
+                Description: userDescription,
+                Required:    true,
+            },
+        },
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (rc *Command) handleCommandKick(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    user := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
+
+    if response, err := verifyModerationTarget(i, channel, user, false); err != nil {
+        log.Warn().Printf("room: kick command: %v", err)
+        return response
+    }
+
+    if voiceState, err := s.State.VoiceState(i.GuildID, user.ID); err != nil || voiceState.ChannelID != channel.Id {
+        log.Warn().Printf("room: kick command: user %s is not in room %s", user.ID, channel.Id)
+        return model.CommandWarning(
+            fmt.Sprintf("%s is not in your room!", user.Mention()),
+        )
+    }
+
+    if err := s.GuildMemberMove(i.GuildID, user.ID, nil); err != nil {
+        log.Error().Printf("room: kick command: unable to disconnect %s from room %s: %v", user.ID, channel.Id, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to disconnect %s.", user.Mention()),
+        )
+    }
+
+    log.Info().Printf("room: kick command: user %s kicked from room %s", user.ID, channel.Id)
+    return model.CommandSuccess(
+        fmt.Sprintf("%s was disconnected from the room.", user.Mention()),
+    )
+}
+
+func (rc *Command) handleCommandBan(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    user := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
+
+    if response, err := verifyModerationTarget(i, channel, user, true); err != nil {
+        log.Warn().Printf("room: ban command: %v", err)
+        return response
+    }
+
+    permit := model.ChannelPermit{
+        ChannelID: channel.Id,
+        UserID:    user.ID,
+        Allowed:   false,
+    }
+
+    if err := commands.SetRoomPermit(s, rc.channelPermitsRepository, permit); err != nil {
+        log.Error().Printf("room: ban command: unable to deny %s in room %s: %v", user.ID, channel.Id, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to ban %s.", user.Mention()),
+        )
+    }
+
+    ban := model.RoomBan{
+        OwnerID: channel.OwnerID,
+        GuildID: i.GuildID,
+        UserID:  user.ID,
+    }
+
+    if err := rc.roomBansRepository.SetRoomBan(&ban); err != nil {
+        log.Error().Printf("room: ban command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to remember the ban of %s.", user.Mention()),
+        )
+    }
+
+    if voiceState, err := s.State.VoiceState(i.GuildID, user.ID); err == nil && voiceState.ChannelID == channel.Id {
+        if err := s.GuildMemberMove(i.GuildID, user.ID, nil); err != nil {
+            log.Error().Printf("room: ban command: unable to disconnect %s from room %s: %v", user.ID, channel.Id, err)
+        }
+    }
+
+    log.Info().Printf("room: ban command: user %s banned from rooms of %s by %s", user.ID, channel.OwnerID, i.Member.User.ID)
+    if channel.OwnerID != i.Member.User.ID {
+        return model.CommandSuccess(
+            fmt.Sprintf("%s is banned from the rooms of <@%s>.", user.Mention(), channel.OwnerID),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("%s is banned from your rooms.", user.Mention()),
+    )
+}
+
+func (rc *Command) handleCommandUnban(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    user := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
+
+    ban := model.RoomBan{
+        OwnerID: channel.OwnerID,
+        GuildID: i.GuildID,
+        UserID:  user.ID,
+    }
+
+    affectedRows, err := rc.roomBansRepository.DeleteRoomBan(&ban)
+    if err != nil {
+        log.Error().Printf("room: unban command: %v", err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to unban %s.", user.Mention()),
+        )
+    }
+
+    if affectedRows == 0 {
+        log.Warn().Printf("room: unban command: user %s is not banned by %s", user.ID, channel.OwnerID)
+        return model.CommandWarning(
+            fmt.Sprintf("%s is not banned!", user.Mention()),
+        )
+    }
+
+    log.Info().Printf("room: unban command: user %s unbanned by %s", user.ID, channel.OwnerID)
+    return model.CommandSuccess(
+        fmt.Sprintf("%s can join your next rooms again, use `/room permit` to let them in this one.", user.Mention()),
+    )
+}
+
+// verifyModerationTarget does not let callers kick or ban themselves, and owners cannot be banned from their own rooms.
+func verifyModerationTarget(
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+    user *discordgo.User,
+    isBan bool,
+) (model.CommandResponse, error) {
+    if user.ID == i.Member.User.ID {
+        return model.CommandWarning("You cannot do this to yourself!"),
+            fmt.Errorf("user %s cannot moderate themselves", user.ID)
+    }
+
+    if isBan && user.ID == channel.OwnerID {
+        return model.CommandWarning(
+                fmt.Sprintf("%s owns the room and cannot be banned from it!", user.Mention()),
+            ),
+            fmt.Errorf("owner %s cannot be banned from room %s", user.ID, channel.Id)
+    }
+
+    return model.CommandResponse{}, nil
+}
+
+// isModeration tells whether server admins can run the subcommand in rooms they do not own.
+func isModeration(slashCommand string) bool {
+    return slashCommand == commandKick || slashCommand == commandBan
+}
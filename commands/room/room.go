@@ -0,0 +1,474 @@
+package room
+
+import (
+    "fmt"
+    "hometown-bot/commands"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/repository"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+const (
+    room          string = "room"   // Command group
+    commandRename string = "rename" // Subcommand room rename
+    commandLimit  string = "limit"  // Subcommand room limit
+    commandLock   string = "lock"   // Subcommand room lock
+    commandUnlock string = "unlock" // Subcommand room unlock
+    commandHide   string = "hide"   // Subcommand room hide
+    commandPermit string = "permit" // Subcommand room permit
+    commandDeny   string = "deny"   // Subcommand room deny
+    commandClaim  string = "claim"  // Subcommand room claim
+    commandSave   string = "save"   // Subcommand room save
+    optionName    string = "name"   // Option for commandRename
+    optionLimit   string = "limit"  // Option for commandLimit
+    optionUser    string = "user"   // Option for commandPermit, commandDeny, commandKick, commandBan, commandUnban
+)
+
+var (
+    dmPermission bool    = false                 // Does not allow using Bot in DMs
+    minLimit     float64 = 0                     // Zero means unlimited room
+    Commands             = getRoomCommandGroup() // Command group
+)
+
+type Command struct {
+    channelRepository         repository.ChannelRepository
+    channelPermitsRepository  repository.ChannelPermitsRepository
+    userPreferencesRepository repository.UserPreferencesRepository
+    roomBansRepository        repository.RoomBansRepository
+    commandHandlers           map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) // Command interaction
+}
+
+func New(
+    channelRepository repository.ChannelRepository,
+    channelPermitsRepository repository.ChannelPermitsRepository,
+    userPreferencesRepository repository.UserPreferencesRepository,
+    roomBansRepository repository.RoomBansRepository,
+) *Command {
+    commands := Command{
+        channelRepository:         channelRepository,
+        channelPermitsRepository:  channelPermitsRepository,
+        userPreferencesRepository: userPreferencesRepository,
+        roomBansRepository:        roomBansRepository,
+    }
+
+    commands.commandHandlers = commands.createCommandHandlers()
+    return &commands
+}
+
+func (rc *Command) HandleSlashCommands(discord *discordgo.Session, interaction *discordgo.InteractionCreate) {
+    if interaction.Type != discordgo.InteractionApplicationCommand {
+        return
+    }
+
+    if handler, ok := rc.commandHandlers[interaction.ApplicationCommandData().Name]; ok {
+        handler(discord, interaction)
+    }
+}
+
+/* ------ COMMANDS ------ */
+
+func getRoomCommandGroup() []*discordgo.ApplicationCommand {
+    return []*discordgo.ApplicationCommand{
+        {
+            Name:         room,
+            Description:  "Room owner's commands group.",
+            DMPermission: &dmPermission,
+            Options: []*discordgo.ApplicationCommandOption{
+                getRenameCommand(),
+                getLimitCommand(),
+                getLockCommand(),
+                getUnlockCommand(),
+                getHideCommand(),
+                getPermitCommand(),
+                getDenyCommand(),
+                getClaimCommand(),
+                getSaveCommand(),
+                getKickCommand(),
+                getBanCommand(),
+                getUnbanCommand(),
+            },
+        },
+    }
+}
+
+func getRenameCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandRename,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Rename your room.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionString,
+                Name:        optionName,
+                Description: "A new room name.",
+                MaxLength:   100,
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getLimitCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandLimit,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Change your room capacity.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionInteger,
+                Name:        optionLimit,
+                Description: "A new room capacity, 0 is unlimited.",
+                MinValue:    &minLimit,
+                MaxValue:    99,
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getLockCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandLock,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Do not allow anyone else to join your room.",
+    }
+}
+
+func getUnlockCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandUnlock,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Allow everyone to see and join your room.",
+    }
+}
+
+func getHideCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandHide,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Hide your room from everyone else.",
+    }
+}
+
+func getPermitCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandPermit,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Allow a user to see and join your room.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionUser,
+                Name:        optionUser,
+                Description: "A user to be permitted.",
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getDenyCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandDeny,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Do not allow a user to join your room.",
+        Options: []*discordgo.ApplicationCommandOption{
+            {
+                Type:        discordgo.ApplicationCommandOptionUser,
+                Name:        optionUser,
+                Description: "A user to be denied.",
+                Required:    true,
+            },
+        },
+    }
+}
+
+func getClaimCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandClaim,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Become the owner of the room if its owner is gone.",
+    }
+}
+
+func getSaveCommand() *discordgo.ApplicationCommandOption {
+    return &discordgo.ApplicationCommandOption{
+        Name:        commandSave,
+        Type:        discordgo.ApplicationCommandOptionSubCommand,
+        Description: "Save name, limit, privacy and permitted users of your room for the next time.",
+    }
+}
+
+/* ------ INTERACTIONS ------ */
+
+func (rc *Command) createCommandHandlers() map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+    return map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+        room: func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+            log.Info().Printf("trigger %s command interaction", room)
+
+            slashCommand := i.ApplicationCommandData().Options[0].Name
+            commandResponse := model.CommandError(
+                fmt.Sprintf("Oops, something went wrong.\nHol' up, you aren't supposed to see this message."),
+            )
+
+            // Server admins moderate the room they are connected to without owning it
+            getRoom := commands.GetOwnedRoom
+            if isModeration(slashCommand) && i.Member.Permissions&discordgo.PermissionManageServer != 0 {
+                getRoom = commands.GetRoom
+            }
+
+            if slashCommand == commandClaim {
+                commandResponse = rc.handleCommandClaim(s, i)
+            } else if channel, response, err := getRoom(
+                s,
+                rc.channelRepository,
+                i.GuildID,
+                i.Member.User.ID,
+            ); err != nil {
+                log.Warn().Printf("room: %s command: %v", slashCommand, err)
+                commandResponse = response
+            } else {
+                switch slashCommand {
+                case commandRename:
+                    commandResponse = rc.handleCommandRename(s, i, channel)
+                case commandLimit:
+                    commandResponse = rc.handleCommandLimit(s, i, channel)
+                case commandLock:
+                    commandResponse = rc.handleCommandPrivacy(s, i, channel, model.PrivacyLocked)
+                case commandHide:
+                    commandResponse = rc.handleCommandPrivacy(s, i, channel, model.PrivacyHidden)
+                case commandUnlock:
+                    commandResponse = rc.handleCommandPrivacy(s, i, channel, model.PrivacyOpen)
+                case commandPermit:
+                    commandResponse = rc.handleCommandPermit(s, i, channel, true)
+                case commandDeny:
+                    commandResponse = rc.handleCommandPermit(s, i, channel, false)
+                case commandSave:
+                    commandResponse = rc.handleCommandSave(s, i, channel)
+                case commandKick:
+                    commandResponse = rc.handleCommandKick(s, i, channel)
+                case commandBan:
+                    commandResponse = rc.handleCommandBan(s, i, channel)
+                case commandUnban:
+                    commandResponse = rc.handleCommandUnban(s, i, channel)
+                }
+            }
+
+            log.Info().Printf("room: sending interaction response for %s", slashCommand)
+            if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+                Type: discordgo.InteractionResponseChannelMessageWithSource,
+                Data: &discordgo.InteractionResponseData{
+                    Embeds: []*discordgo.MessageEmbed{
+                        commandResponse.ToEmbededMessage(),
+                    },
+                    Flags: discordgo.MessageFlagsEphemeral,
+                },
+            }); err != nil {
+                log.Error().Printf("room: interaction response: %v", err)
+            }
+        },
+    }
+}
+
+func (rc *Command) handleCommandRename(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    name := options[0].Options[0].StringValue()
+
+    if _, err := s.ChannelEdit(channel.Id, &discordgo.ChannelEdit{Name: name}); err != nil {
+        log.Error().Printf("room: rename command: unable to rename room %s to %s: %v", channel.Id, name, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to rename the room to \"%s\".", name),
+        )
+    }
+
+    if channel.TextID != "" {
+        if _, err := s.ChannelEdit(channel.TextID, &discordgo.ChannelEdit{Name: name}); err != nil {
+            log.Error().Printf("room: rename command: unable to rename text channel %s to %s: %v", channel.TextID, name, err)
+        }
+    }
+
+    log.Info().Printf("room: rename command: room %s renamed to %s", channel.Id, name)
+    return model.CommandSuccess(
+        fmt.Sprintf("Room successfully renamed to \"%s\".", name),
+    )
+}
+
+func (rc *Command) handleCommandLimit(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    limit := options[0].Options[0].IntValue()
+
+    // ChannelEdit omits zero user limit, so the raw request is used to make the room unlimited
+    endpoint := discordgo.EndpointChannel(channel.Id)
+    data := map[string]any{"user_limit": limit}
+    if _, err := s.RequestWithBucketID("PATCH", endpoint, data, endpoint); err != nil {
+        log.Error().Printf("room: limit command: unable to set limit %d for room %s: %v", limit, channel.Id, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to set room capacity to %d.", limit),
+        )
+    }
+
+    log.Info().Printf("room: limit command: room %s capacity set to %d", channel.Id, limit)
+    if limit == 0 {
+        return model.CommandSuccess("Room capacity successfully set to unlimited.")
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("Room capacity successfully set to %d.", limit),
+    )
+}
+
+func (rc *Command) handleCommandPrivacy(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+    privacy model.Privacy,
+) model.CommandResponse {
+    var state string
+    switch privacy {
+    case model.PrivacyLocked:
+        state = "locked"
+    case model.PrivacyHidden:
+        state = "hidden"
+    default:
+        state = "opened"
+    }
+
+    if err := commands.SetRoomPrivacy(s, rc.channelRepository, i.GuildID, channel, privacy); err != nil {
+        log.Error().Printf("room: privacy command: unable to set privacy %d for room %s: %v", privacy, channel.Id, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to make the room %s.", state),
+        )
+    }
+
+    log.Info().Printf("room: privacy command: room %s %s", channel.Id, state)
+    return model.CommandSuccess(
+        fmt.Sprintf("Room successfully %s.", state),
+    )
+}
+
+func (rc *Command) handleCommandPermit(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+    allowed bool,
+) model.CommandResponse {
+    options := i.ApplicationCommandData().Options
+    user := options[0].Options[0].UserValue(s)
+
+    if user.ID == channel.OwnerID {
+        log.Warn().Printf("room: permit command: owner %s cannot permit or deny themselves", user.ID)
+        return model.CommandWarning("You cannot change your own access to the room!")
+    }
+
+    permit := model.ChannelPermit{
+        ChannelID: channel.Id,
+        UserID:    user.ID,
+        Allowed:   allowed,
+    }
+
+    if err := commands.SetRoomPermit(s, rc.channelPermitsRepository, permit); err != nil {
+        log.Error().Printf("room: permit command: unable to set permit %t for %s in room %s: %v", allowed, user.ID, channel.Id, err)
+        return model.CommandError(
+            fmt.Sprintf("Unable to change room access for %s.", user.Mention()),
+        )
+    }
+
+    log.Info().Printf("room: permit command: permit %t set for %s in room %s", allowed, user.ID, channel.Id)
+    if allowed {
+        return model.CommandSuccess(
+            fmt.Sprintf("%s can now join the room.", user.Mention()),
+        )
+    }
+
+    return model.CommandSuccess(
+        fmt.Sprintf("%s cannot join the room anymore.", user.Mention()),
+    )
+}
+
+func (rc *Command) handleCommandClaim(s *discordgo.Session, i *discordgo.InteractionCreate) model.CommandResponse {
+    userId := i.Member.User.ID
+
+    channel, response, err := commands.GetRoom(s, rc.channelRepository, i.GuildID, userId)
+    if err != nil {
+        log.Warn().Printf("room: claim command: %v", err)
+        return response
+    }
+
+    if channel.OwnerID == userId {
+        log.Warn().Printf("room: claim command: user %s already owns room %s", userId, channel.Id)
+        return model.CommandWarning("You already own this room!")
+    }
+
+    if ownerState, err := s.State.VoiceState(i.GuildID, channel.OwnerID); err == nil && ownerState.ChannelID == channel.Id {
+        log.Warn().Printf("room: claim command: owner %s is still in room %s", channel.OwnerID, channel.Id)
+        return model.CommandWarning("The room owner is still here!")
+    }
+
+    if err := commands.TransferRoom(s, rc.channelRepository, channel, userId); err != nil {
+        log.Error().Printf("room: claim command: unable to transfer room %s to %s: %v", channel.Id, userId, err)
+        return model.CommandError("Unable to claim the room.")
+    }
+
+    log.Info().Printf("room: claim command: room %s claimed by %s", channel.Id, userId)
+    return model.CommandSuccess("You are now the owner of this room.")
+}
+
+func (rc *Command) handleCommandSave(
+    s *discordgo.Session,
+    i *discordgo.InteractionCreate,
+    channel model.Channel,
+) model.CommandResponse {
+    voiceChannel, err := s.State.Channel(channel.Id)
+    if err != nil {
+        voiceChannel, err = s.Channel(channel.Id)
+        if err != nil {
+            log.Error().Printf("room: save command: unable to get room %s: %v", channel.Id, err)
+            return model.CommandError("Unable to get the room!")
+        }
+    }
+
+    permits, err := rc.channelPermitsRepository.GetChannelPermits(channel.Id)
+    if err != nil {
+        log.Error().Printf("room: save command: %v", err)
+        return model.CommandError("Unable to get permitted users of the room!")
+    }
+
+    preference := model.UserPreference{
+        UserID:    i.Member.User.ID,
+        GuildID:   i.GuildID,
+        Name:      voiceChannel.Name,
+        UserLimit: voiceChannel.UserLimit,
+        Privacy:   channel.Privacy,
+    }
+
+    for _, permit := range permits {
+        if permit.Allowed {
+            preference.Permits = append(preference.Permits, permit.UserID)
+        }
+    }
+
+    if err := rc.userPreferencesRepository.SetUserPreference(&preference); err != nil {
+        log.Error().Printf("room: save command: %v", err)
+        return model.CommandError("Unable to save room preferences.")
+    }
+
+    log.Info().Printf("room: save command: preferences of room %s saved for %s", channel.Id, preference.UserID)
+    return model.CommandSuccess(
+        fmt.Sprintf(
+            "Room preferences saved: \"%s\", limit %d, %d permitted users.\n"+
+                "They are applied to your new rooms in lobbies that allow it.",
+            preference.Name,
+            preference.UserLimit,
+            len(preference.Permits),
+        ),
+    )
+}
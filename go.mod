@@ -5,7 +5,9 @@ go 1.22.3
 require (
 	github.com/bwmarrin/discordgo v0.28.1
 	github.com/fatih/color v1.17.0
+	github.com/lib/pq v1.12.3
 	github.com/mattn/go-sqlite3 v1.14.22
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -2,14 +2,35 @@ package main
 
 import (
     "database/sql"
+    "flag"
     "hometown-bot/bot"
     "hometown-bot/log"
     "hometown-bot/repository"
+    "hometown-bot/repository/memory"
     "hometown-bot/storage"
     "os"
 )
 
+type repositories struct {
+    channel         repository.ChannelRepository
+    channelMembers  repository.ChannelMembersRepository
+    channelPermits  repository.ChannelPermitsRepository
+    lobby           repository.LobbyRepository
+    userPreferences repository.UserPreferencesRepository
+    guild           repository.GuildRepository
+    lobbyRoles      repository.LobbyRolesRepository
+    roomBans        repository.RoomBansRepository
+    lobbyOverwrites repository.LobbyOverwritesRepository
+    lobbyOverflows  repository.LobbyOverflowsRepository
+    transactor      repository.Transactor
+}
+
 func main() {
+    inMemory := flag.Bool("memory", false, "keep all data in memory, it is lost once the bot stops")
+    skipBackup := flag.Bool("skip-backup", false, "migrate the storage schema without backing up the database")
+    presences := flag.Bool("presences", false, "request the presence intent for %game% and activity renaming")
+    flag.Parse()
+
     log.Info().Println("env: reading keys")
     botToken, ok := os.LookupEnv("BOT_TOKEN")
     if !ok {
@@ -17,33 +38,106 @@ func main() {
         os.Exit(1)
     }
 
-    log.Info().Println("storage: initializing")
-    db, err := storage.Load()
-    if err != nil {
-        log.Error().Printf("storage: %v", err)
-        os.Exit(1)
-    }
+    var repos repositories
+    if *inMemory {
+        log.Warn().Println("storage: running in memory, data is lost once the bot stops")
+        repos = newMemoryRepositories()
+    } else {
+        dsn, ok := os.LookupEnv("STORAGE_DSN")
+        if !ok {
+            dsn = storage.DefaultDSN
+        }
 
-    defer func(db *sql.DB) {
-        log.Info().Println("storage: closing")
-        err := db.Close()
+        log.Info().Println("storage: initializing")
+        db, dialect, err := storage.Load(dsn, *skipBackup)
         if err != nil {
             log.Error().Printf("storage: %v", err)
             os.Exit(1)
         }
-    }(db)
 
-    log.Info().Println("repository: initializing")
-    channelRepository := repository.NewChannel(db)
-    channelMembersRepository := repository.NewChannelMembers(db)
-    lobbyRepository := repository.NewLobby(db)
+        defer func(db *sql.DB) {
+            log.Info().Println("storage: closing")
+            err := db.Close()
+            if err != nil {
+                log.Error().Printf("storage: %v", err)
+                os.Exit(1)
+            }
+        }(db)
+
+        repos = newSQLRepositories(db, dialect)
+    }
 
     log.Info().Println("bot: initializing")
     bot.Token = botToken
-    b := bot.Create(*channelRepository, *channelMembersRepository, *lobbyRepository)
+    bot.Presences = *presences
+    b := bot.Create(
+        repos.channel,
+        repos.channelMembers,
+        repos.channelPermits,
+        repos.lobby,
+        repos.userPreferences,
+        repos.guild,
+        repos.lobbyRoles,
+        repos.roomBans,
+        repos.lobbyOverwrites,
+        repos.lobbyOverflows,
+        repos.transactor,
+    )
 
     if err := b.Run(); err != nil {
         log.Error().Printf("bot: %v", err)
         os.Exit(1)
     }
 }
+
+func newSQLRepositories(db *sql.DB, dialect storage.Dialect) repositories {
+    log.Info().Println("repository: initializing")
+    return repositories{
+        channel:         repository.NewChannel(db, dialect),
+        channelMembers:  repository.NewChannelMembers(db, dialect),
+        channelPermits:  repository.NewChannelPermits(db, dialect),
+        lobby:           repository.NewLobby(db, dialect),
+        userPreferences: repository.NewUserPreferences(db, dialect),
+        guild:           repository.NewGuild(db, dialect),
+        lobbyRoles:      repository.NewLobbyRoles(db, dialect),
+        roomBans:        repository.NewRoomBans(db, dialect),
+        lobbyOverwrites: repository.NewLobbyOverwrites(db, dialect),
+        lobbyOverflows:  repository.NewLobbyOverflows(db, dialect),
+        transactor:      repository.NewTransactor(db, dialect),
+    }
+}
+
+func newMemoryRepositories() repositories {
+    log.Info().Println("repository: initializing in memory")
+    channels := memory.NewChannel()
+    channelMembers := memory.NewChannelMembers()
+    channelPermits := memory.NewChannelPermits()
+    guilds := memory.NewGuild()
+    lobbies := memory.NewLobby()
+    lobbyRoles := memory.NewLobbyRoles()
+    lobbyOverwrites := memory.NewLobbyOverwrites()
+    lobbyOverflows := memory.NewLobbyOverflows()
+
+    return repositories{
+        channel:         channels,
+        channelMembers:  channelMembers,
+        channelPermits:  channelPermits,
+        lobby:           lobbies,
+        userPreferences: memory.NewUserPreferences(),
+        guild:           guilds,
+        lobbyRoles:      lobbyRoles,
+        roomBans:        memory.NewRoomBans(),
+        lobbyOverwrites: lobbyOverwrites,
+        lobbyOverflows:  lobbyOverflows,
+        transactor: memory.NewTransactor(
+            channels,
+            channelMembers,
+            channelPermits,
+            guilds,
+            lobbies,
+            lobbyRoles,
+            lobbyOverwrites,
+            lobbyOverflows,
+        ),
+    }
+}
@@ -6,23 +6,132 @@ import (
     "hometown-bot/util/discord"
 )
 
+type Naming int
+
+const (
+    NamingTemplate Naming = iota // Rooms are named after the lobby template
+    NamingNumbered               // Rooms are numbered within the lobby, "Duo #1"
+)
+
+type TextChannel int
+
+const (
+    TextChannelNone    TextChannel = iota // Rooms have no companion text channel
+    TextChannelDelete                     // Text channel is deleted together with the room
+    TextChannelArchive                    // Text channel is kept, but hidden from the members, once the room is deleted
+)
+
+type PermissionSource int
+
+const (
+    PermissionSourceCategory PermissionSource = iota // Rooms copy permission overwrites of the category
+    PermissionSourceLobby                            // Rooms copy permission overwrites of the lobby channel
+    PermissionSourceCustom                           // Rooms get the overwrites stored for the lobby
+)
+
+type Placement int
+
+const (
+    PlacementBelow  Placement = iota // New rooms are placed directly below the lobby
+    PlacementBottom                  // New rooms are placed at the bottom of the category
+    PlacementIndex                   // Rooms are sorted by their index below the lobby
+)
+
 type Lobby struct {
-    Id         string
+    Id           string
+    CategoryID   string
+    GuildID      string
+    Template     sql.NullString
+    Capacity     sql.NullInt32
+    Naming       sql.NullInt32
+    Grace        sql.NullInt32
+    Bitrate      sql.NullInt32
+    RTCRegion    sql.NullString
+    VideoQuality sql.NullInt32
+    NSFW         sql.NullBool
+    TextChannel  sql.NullInt32
+    Activity     sql.NullBool
+    Overrides    sql.NullBool  // Whether saved user preferences are applied instead of the lobby settings
+    Cooldown     sql.NullInt32 // Seconds between two rooms of the same user, no cooldown if NULL or 0
+    BurstLimit   sql.NullInt32 // Rooms a user can create within 5 minutes, unlimited if NULL or 0
+    BlockSpam    sql.NullBool
+    MaxRooms     sql.NullInt32
+    WaitingID    sql.NullString // Waiting room to knock on locked channels
+    Permissions  sql.NullInt32  // Source of permission overwrites of new rooms
+    Placement    sql.NullInt32  // Position of new rooms in the category
+    AutoOverflow sql.NullBool   // Whether overflow categories are created once all categories are full
+}
+
+type LobbyOverflow struct {
+    LobbyID    string
     CategoryID string
-    GuildID    string
-    Template   sql.NullString
-    Capacity   sql.NullInt32
+    Position   int // Order in which overflow categories are used
+}
+
+type LobbyOverwrite struct {
+    LobbyID  string
+    TargetID string
+    Type     discordgo.PermissionOverwriteType
+    Allow    int64
+    Deny     int64
+}
+
+type LobbyRole struct {
+    LobbyID string
+    RoleID  string
+    Allowed bool
+}
+
+type Guild struct {
+    Id       string
+    MaxRooms sql.NullInt32
 }
 
+type Privacy int
+
+const (
+    PrivacyOpen   Privacy = iota // Everyone can see and join
+    PrivacyLocked                // Everyone can see, but only permitted users can join
+    PrivacyHidden                // Only permitted users can see and join
+)
+
 type Channel struct {
     Id       string
     ParentID string
+    GuildID  string
+    OwnerID  string
+    Privacy  Privacy
+    Index    int
+    DeleteAt int64  // Unix time of the pending deletion, 0 if the channel is not empty
+    TextID   string // Companion text channel, empty if the lobby has none
+}
+
+type ChannelPermit struct {
+    ChannelID string
+    UserID    string
+    Allowed   bool
+}
+
+type RoomBan struct {
+    OwnerID string
+    GuildID string
+    UserID  string
+}
+
+type UserPreference struct {
+    UserID    string
+    GuildID   string
+    Name      string
+    UserLimit int
+    Privacy   Privacy
+    Permits   []string // Users permitted to join the room
 }
 
 type CommandResponse struct {
     Title       string
     Description string
     ColorType   discord.Color
+    Fields      []*discordgo.MessageEmbedField // Optional, e.g. one field per listed item
 }
 
 func CommandSuccess(description string) CommandResponse {
@@ -54,5 +163,6 @@ func (c CommandResponse) ToEmbededMessage() *discordgo.MessageEmbed {
         Title:       c.Title,
         Description: c.Description,
         Color:       discord.GetColorFrom(c.ColorType),
+        Fields:      c.Fields,
     }
 }
@@ -5,52 +5,80 @@ import (
     "fmt"
     "hometown-bot/log"
     "hometown-bot/model"
+    "hometown-bot/storage"
 )
 
-type ChannelRepository struct {
-    db *sql.DB
+type SQLChannelRepository struct {
+    db      querier
+    dialect storage.Dialect
 }
 
-func NewChannel(db *sql.DB) *ChannelRepository {
-    return &ChannelRepository{db: db}
+func NewChannel(db *sql.DB, dialect storage.Dialect) *SQLChannelRepository {
+    return &SQLChannelRepository{db: db, dialect: dialect}
 }
 
 const SelectChannelById = `
-SELECT id, parent_id
+SELECT id, parent_id, guild_id, coalesce(owner_id, ''), coalesce(privacy, 0), coalesce(idx, 0),
+    coalesce(delete_at, 0), coalesce(text_id, '')
 FROM channels
-WHERE id = ?
+WHERE (id = ? AND guild_id = ?)
 `
 
-func (cr *ChannelRepository) GetChannel(id string) (model.Channel, error) {
+func (cr *SQLChannelRepository) GetChannel(id string, guildId string) (model.Channel, error) {
     var channel model.Channel
 
-    log.Debug().Printf("repo: get channel %s", id)
-    if err := cr.db.QueryRow(SelectChannelById, &channel.Id, &channel.ParentID).Scan(); err != nil {
-        return model.Channel{}, fmt.Errorf("repo: unable to get channel[%s]: %w", id, err)
+    log.Debug().Printf("repo: get channel[%s] for guild[%s]", id, guildId)
+    if err := cr.db.QueryRow(
+        cr.dialect.Query(SelectChannelById),
+        id,
+        guildId,
+    ).Scan(
+        &channel.Id,
+        &channel.ParentID,
+        &channel.GuildID,
+        &channel.OwnerID,
+        &channel.Privacy,
+        &channel.Index,
+        &channel.DeleteAt,
+        &channel.TextID,
+    ); err != nil {
+        return model.Channel{}, fmt.Errorf("repo: unable to get channel[%s] for guild[%s]: %w", id, guildId, err)
     }
 
     return channel, nil
 }
 
 const SelectChannels = `
-SELECT id, parent_id
+SELECT id, parent_id, guild_id, coalesce(owner_id, ''), coalesce(privacy, 0), coalesce(idx, 0),
+    coalesce(delete_at, 0), coalesce(text_id, '')
 FROM channels
+WHERE guild_id = ?
 `
 
-func (cr *ChannelRepository) GetChannels() ([]model.Channel, error) {
-    log.Debug().Println("repo: get channels")
+func (cr *SQLChannelRepository) GetChannels(guildId string) ([]model.Channel, error) {
+    log.Debug().Printf("repo: get channels for guild[%s]", guildId)
 
-    rows, err := cr.db.Query(SelectChannels)
+    rows, err := cr.db.Query(cr.dialect.Query(SelectChannels), guildId)
     if err != nil {
-        return []model.Channel{}, fmt.Errorf("repo: unable to get channels: %w", err)
+        return []model.Channel{}, fmt.Errorf("repo: unable to get channels for guild[%s]: %w", guildId, err)
     }
+    defer rows.Close()
 
     var channels []model.Channel
     for rows.Next() {
         var channel model.Channel
 
-        if err := rows.Scan(&channel.Id, &channel.ParentID); err != nil {
-            return nil, fmt.Errorf("repo: unable to get channels: %w", err)
+        if err := rows.Scan(
+            &channel.Id,
+            &channel.ParentID,
+            &channel.GuildID,
+            &channel.OwnerID,
+            &channel.Privacy,
+            &channel.Index,
+            &channel.DeleteAt,
+            &channel.TextID,
+        ); err != nil {
+            return nil, fmt.Errorf("repo: unable to get channels for guild[%s]: %w", guildId, err)
         }
 
         channels = append(channels, channel)
@@ -59,30 +87,134 @@ func (cr *ChannelRepository) GetChannels() ([]model.Channel, error) {
     return channels, nil
 }
 
+const SelectChannelIndexes = `
+SELECT idx
+FROM channels
+WHERE (parent_id = ? AND idx IS NOT NULL)
+ORDER BY idx ASC
+`
+
+func (cr *SQLChannelRepository) GetChannelIndexes(parentId string) ([]int, error) {
+    log.Debug().Printf("repo: get channel indexes for lobby[%s]", parentId)
+
+    rows, err := cr.db.Query(cr.dialect.Query(SelectChannelIndexes), parentId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get channel indexes for lobby[%s]: %w", parentId, err)
+    }
+    defer rows.Close()
+
+    var indexes []int
+    for rows.Next() {
+        var index int
+
+        if err := rows.Scan(&index); err != nil {
+            return nil, fmt.Errorf("repo: unable to get channel indexes for lobby[%s]: %w", parentId, err)
+        }
+
+        indexes = append(indexes, index)
+    }
+
+    return indexes, nil
+}
+
+// ReplaceChannel updates the stored channel in place, a REPLACE would silently drop the channel holding its index.
 const ReplaceChannel = `
-REPLACE INTO channels (id, parent_id)
-VALUES(?, ?)
+INSERT INTO channels (id, parent_id, guild_id, owner_id, privacy, idx, text_id)
+VALUES(?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id)
+DO UPDATE
+SET
+	parent_id = EXCLUDED.parent_id,
+	guild_id = EXCLUDED.guild_id,
+	owner_id = EXCLUDED.owner_id,
+	privacy = EXCLUDED.privacy,
+	idx = EXCLUDED.idx,
+	delete_at = NULL,
+	text_id = EXCLUDED.text_id
 `
 
-func (cr *ChannelRepository) SetChannel(channel *model.Channel) error {
+func (cr *SQLChannelRepository) SetChannel(channel *model.Channel) error {
     log.Debug().Printf("repo: set channel[%s]", channel.Id)
 
-    if _, err := cr.db.Exec(ReplaceChannel, channel.Id, channel.ParentID); err != nil {
+    if _, err := cr.db.Exec(
+        cr.dialect.Query(ReplaceChannel),
+        channel.Id,
+        channel.ParentID,
+        channel.GuildID,
+        channel.OwnerID,
+        channel.Privacy,
+        channel.Index,
+        channel.TextID,
+    ); err != nil {
         return fmt.Errorf("repo: unable to set channel[%s]: %w", channel.Id, err)
     }
 
     return nil
 }
 
+const UpdateChannelOwner = `
+UPDATE channels
+SET owner_id = ?
+WHERE id = ?
+`
+
+func (cr *SQLChannelRepository) SetChannelOwner(id string, ownerId string) error {
+    log.Debug().Printf("repo: set channel[%s] owner[%s]", id, ownerId)
+
+    if _, err := cr.db.Exec(cr.dialect.Query(UpdateChannelOwner), ownerId, id); err != nil {
+        return fmt.Errorf("repo: unable to set channel[%s] owner[%s]: %w", id, ownerId, err)
+    }
+
+    return nil
+}
+
+const UpdateChannelPrivacy = `
+UPDATE channels
+SET privacy = ?
+WHERE id = ?
+`
+
+func (cr *SQLChannelRepository) SetChannelPrivacy(id string, privacy model.Privacy) error {
+    log.Debug().Printf("repo: set channel[%s] privacy %d", id, privacy)
+
+    if _, err := cr.db.Exec(cr.dialect.Query(UpdateChannelPrivacy), privacy, id); err != nil {
+        return fmt.Errorf("repo: unable to set channel[%s] privacy %d: %w", id, privacy, err)
+    }
+
+    return nil
+}
+
+const UpdateChannelDeleteAt = `
+UPDATE channels
+SET delete_at = ?
+WHERE id = ?
+`
+
+// SetChannelDeleteAt stores unix time of the pending deletion, zero clears it.
+func (cr *SQLChannelRepository) SetChannelDeleteAt(id string, deleteAt int64) error {
+    log.Debug().Printf("repo: set channel[%s] delete at %d", id, deleteAt)
+
+    var value sql.NullInt64
+    if deleteAt > 0 {
+        value = sql.NullInt64{Valid: true, Int64: deleteAt}
+    }
+
+    if _, err := cr.db.Exec(cr.dialect.Query(UpdateChannelDeleteAt), value, id); err != nil {
+        return fmt.Errorf("repo: unable to set channel[%s] delete at %d: %w", id, deleteAt, err)
+    }
+
+    return nil
+}
+
 const DeleteChannel = `
 DELETE FROM channels
 WHERE id = ?
 `
 
-func (cr *ChannelRepository) DeleteChannel(id string) error {
+func (cr *SQLChannelRepository) DeleteChannel(id string) error {
     log.Debug().Printf("repo: delete channel[%s]", id)
 
-    if _, err := cr.db.Exec(DeleteChannel, id); err != nil {
+    if _, err := cr.db.Exec(cr.dialect.Query(DeleteChannel), id); err != nil {
         return fmt.Errorf("repo: unable to delete channel[%s]: %w", id, err)
     }
 
@@ -4,14 +4,17 @@ import (
     "database/sql"
     "fmt"
     "hometown-bot/log"
+    "hometown-bot/storage"
+    "time"
 )
 
-type ChannelMembersRepository struct {
-    db *sql.DB
+type SQLChannelMembersRepository struct {
+    db      querier
+    dialect storage.Dialect
 }
 
-func NewChannelMembers(db *sql.DB) *ChannelMembersRepository {
-    return &ChannelMembersRepository{db: db}
+func NewChannelMembers(db *sql.DB, dialect storage.Dialect) *SQLChannelMembersRepository {
+    return &SQLChannelMembersRepository{db: db, dialect: dialect}
 }
 
 const CountChannelMembers = `
@@ -19,11 +22,11 @@ SELECT COUNT(*) FROM channel_members
 WHERE (guild_id = ? AND channel_id = ?)
 `
 
-func (cmr *ChannelMembersRepository) GetChannelMembersCount(guildId string, channelId string) (int, error) {
+func (cmr *SQLChannelMembersRepository) GetChannelMembersCount(guildId string, channelId string) (int, error) {
     log.Debug().Printf("repo: get channel[%s] member count for guild[%s]", channelId, guildId)
 
     var output int
-    row := cmr.db.QueryRow(CountChannelMembers, guildId, channelId)
+    row := cmr.db.QueryRow(cmr.dialect.Query(CountChannelMembers), guildId, channelId)
 
     if err := row.Scan(&output); err != nil {
         return 0, fmt.Errorf(
@@ -37,20 +40,68 @@ func (cmr *ChannelMembersRepository) GetChannelMembersCount(guildId string, chan
     return output, nil
 }
 
+const SelectChannelMembers = `
+SELECT user_id FROM channel_members
+WHERE (guild_id = ? AND channel_id = ?)
+ORDER BY joined_at ASC
+`
+
+// GetChannelMembers returns members of the channel, the longest present member goes first.
+func (cmr *SQLChannelMembersRepository) GetChannelMembers(guildId string, channelId string) ([]string, error) {
+    log.Debug().Printf("repo: get channel[%s] members for guild[%s]", channelId, guildId)
+
+    rows, err := cmr.db.Query(cmr.dialect.Query(SelectChannelMembers), guildId, channelId)
+    if err != nil {
+        return nil, fmt.Errorf(
+            "repo: unable to get channel[%s] members for guild[%s]: %w",
+            channelId,
+            guildId,
+            err,
+        )
+    }
+    defer rows.Close()
+
+    var members []string
+    for rows.Next() {
+        var userId string
+
+        if err := rows.Scan(&userId); err != nil {
+            return nil, fmt.Errorf(
+                "repo: unable to get channel[%s] members for guild[%s]: %w",
+                channelId,
+                guildId,
+                err,
+            )
+        }
+
+        members = append(members, userId)
+    }
+
+    return members, nil
+}
+
 const InsertChannelMembers = `
-INSERT INTO channel_members (guild_id, user_id, channel_id)
-VALUES(?, ?, ?)
-ON CONFLICT(user_id) 
+INSERT INTO channel_members (guild_id, user_id, channel_id, joined_at)
+VALUES(?, ?, ?, ?)
+ON CONFLICT(guild_id, user_id)
 DO UPDATE
 SET
-	channel_id = EXCLUDED.channel_id
-WHERE guild_id = ?
+	channel_id = EXCLUDED.channel_id,
+	joined_at = EXCLUDED.joined_at
 `
 
-func (cmr *ChannelMembersRepository) SetChannelMember(guildId string, userId string, channelId string) error {
+// SetChannelMember stamps the join in unix nanoseconds so that members joining within a second keep their order,
+// rows stamped in seconds by older versions still sort first.
+func (cmr *SQLChannelMembersRepository) SetChannelMember(guildId string, userId string, channelId string) error {
     log.Debug().Printf("repo: set channel[%s] member[%s] for guild[%s]", channelId, userId, guildId)
 
-    if _, err := cmr.db.Exec(InsertChannelMembers, guildId, userId, channelId, guildId); err != nil {
+    if _, err := cmr.db.Exec(
+        cmr.dialect.Query(InsertChannelMembers),
+        guildId,
+        userId,
+        channelId,
+        time.Now().UnixNano(),
+    ); err != nil {
         return fmt.Errorf(
             "repo: unable to set channel[%s] member[%s] for guild[%s]: %w",
             channelId,
@@ -68,10 +119,10 @@ DELETE FROM channel_members
 WHERE (guild_id = ? AND user_id = ? AND channel_id = ?)
 `
 
-func (cmr *ChannelMembersRepository) DeleteChannelMember(guildId string, userId string, channelId string) error {
+func (cmr *SQLChannelMembersRepository) DeleteChannelMember(guildId string, userId string, channelId string) error {
     log.Debug().Printf("repo: delete channel[%s] member[%s] for guild[%s]", channelId, userId, guildId)
 
-    if _, err := cmr.db.Exec(DeleteChannelMember, guildId, userId, channelId); err != nil {
+    if _, err := cmr.db.Exec(cmr.dialect.Query(DeleteChannelMember), guildId, userId, channelId); err != nil {
         return fmt.Errorf(
             "repo: unable to delete channel[%s] member[%s] for guild[%s]: %w",
             channelId,
@@ -89,10 +140,10 @@ DELETE FROM channel_members
 WHERE (guild_id = ? AND channel_id = ?)
 `
 
-func (cmr *ChannelMembersRepository) DeleteChannelMembers(guildId string, channelId string) error {
+func (cmr *SQLChannelMembersRepository) DeleteChannelMembers(guildId string, channelId string) error {
     log.Debug().Printf("repo: delete channel[%s] members for guild[%s]", channelId, guildId)
 
-    if _, err := cmr.db.Exec(DeleteChannelMembers, guildId, channelId); err != nil {
+    if _, err := cmr.db.Exec(cmr.dialect.Query(DeleteChannelMembers), guildId, channelId); err != nil {
         return fmt.Errorf(
             "repo: unable to delete channel[%s] members for guild[%s]: %w",
             channelId,
@@ -103,3 +154,18 @@ func (cmr *ChannelMembersRepository) DeleteChannelMembers(guildId string, channe
 
     return nil
 }
+
+const DeleteGuildChannelMembers = `
+DELETE FROM channel_members
+WHERE guild_id = ?
+`
+
+func (cmr *SQLChannelMembersRepository) DeleteGuildChannelMembers(guildId string) error {
+    log.Debug().Printf("repo: delete channel members for guild[%s]", guildId)
+
+    if _, err := cmr.db.Exec(cmr.dialect.Query(DeleteGuildChannelMembers), guildId); err != nil {
+        return fmt.Errorf("repo: unable to delete channel members for guild[%s]: %w", guildId, err)
+    }
+
+    return nil
+}
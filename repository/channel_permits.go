@@ -0,0 +1,86 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLChannelPermitsRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewChannelPermits(db *sql.DB, dialect storage.Dialect) *SQLChannelPermitsRepository {
+    return &SQLChannelPermitsRepository{db: db, dialect: dialect}
+}
+
+const SelectChannelPermits = `
+SELECT channel_id, user_id, allowed
+FROM channel_permits
+WHERE channel_id = ?
+`
+
+func (cpr *SQLChannelPermitsRepository) GetChannelPermits(channelId string) ([]model.ChannelPermit, error) {
+    log.Debug().Printf("repo: get channel[%s] permits", channelId)
+
+    rows, err := cpr.db.Query(cpr.dialect.Query(SelectChannelPermits), channelId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get channel[%s] permits: %w", channelId, err)
+    }
+    defer rows.Close()
+
+    var permits []model.ChannelPermit
+    for rows.Next() {
+        var permit model.ChannelPermit
+
+        if err := rows.Scan(&permit.ChannelID, &permit.UserID, &permit.Allowed); err != nil {
+            return nil, fmt.Errorf("repo: unable to get channel[%s] permits: %w", channelId, err)
+        }
+
+        permits = append(permits, permit)
+    }
+
+    return permits, nil
+}
+
+const InsertChannelPermit = `
+INSERT INTO channel_permits (channel_id, user_id, allowed)
+VALUES(?, ?, ?)
+ON CONFLICT(channel_id, user_id)
+DO UPDATE
+SET
+	allowed = EXCLUDED.allowed
+`
+
+func (cpr *SQLChannelPermitsRepository) SetChannelPermit(permit *model.ChannelPermit) error {
+    log.Debug().Printf("repo: set channel[%s] permit for user[%s]", permit.ChannelID, permit.UserID)
+
+    if _, err := cpr.db.Exec(cpr.dialect.Query(InsertChannelPermit), permit.ChannelID, permit.UserID, permit.Allowed); err != nil {
+        return fmt.Errorf(
+            "repo: unable to set channel[%s] permit for user[%s]: %w",
+            permit.ChannelID,
+            permit.UserID,
+            err,
+        )
+    }
+
+    return nil
+}
+
+const DeleteChannelPermits = `
+DELETE FROM channel_permits
+WHERE channel_id = ?
+`
+
+func (cpr *SQLChannelPermitsRepository) DeleteChannelPermits(channelId string) error {
+    log.Debug().Printf("repo: delete channel[%s] permits", channelId)
+
+    if _, err := cpr.db.Exec(cpr.dialect.Query(DeleteChannelPermits), channelId); err != nil {
+        return fmt.Errorf("repo: unable to delete channel[%s] permits: %w", channelId, err)
+    }
+
+    return nil
+}
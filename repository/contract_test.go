@@ -0,0 +1,378 @@
+package repository_test
+
+import (
+    "database/sql"
+    "errors"
+    "hometown-bot/model"
+    "hometown-bot/repository"
+    "hometown-bot/repository/memory"
+    "hometown-bot/storage"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// Every backend has to pass the same contract, the memory repositories included.
+
+const postgresDSNVariable = "TEST_POSTGRES_DSN"
+
+type repositories struct {
+    lobbies        repository.LobbyRepository
+    channels       repository.ChannelRepository
+    channelMembers repository.ChannelMembersRepository
+}
+
+type backend struct {
+    name string
+    open func(t *testing.T) repositories
+}
+
+func backends() []backend {
+    return []backend{
+        {name: "memory", open: openMemory},
+        {name: "sqlite", open: openSQLite},
+        {name: "postgres", open: openPostgres},
+    }
+}
+
+func openMemory(t *testing.T) repositories {
+    return repositories{
+        lobbies:        memory.NewLobby(),
+        channels:       memory.NewChannel(),
+        channelMembers: memory.NewChannelMembers(),
+    }
+}
+
+func openSQLite(t *testing.T) repositories {
+    return openSQL(t, filepath.Join(t.TempDir(), "storage.db"))
+}
+
+// openPostgres empties the tables under test, the database behind the DSN is expected to be disposable.
+// Locally the backend is skipped without the DSN, CI always has to run it.
+func openPostgres(t *testing.T) repositories {
+    dsn := os.Getenv(postgresDSNVariable)
+    if dsn == "" && os.Getenv("CI") != "" {
+        t.Fatalf("%s is not set, the PostgreSQL contract has to run in CI", postgresDSNVariable)
+    }
+    if dsn == "" {
+        t.Skipf("%s is not set", postgresDSNVariable)
+    }
+
+    return openSQL(t, dsn, "TRUNCATE lobbies, channels, channel_members")
+}
+
+func openSQL(t *testing.T, dsn string, cleanup ...string) repositories {
+    db, dialect, err := storage.Load(dsn, true)
+    if err != nil {
+        t.Fatalf("load storage: %v", err)
+    }
+
+    clean := func() {
+        for _, query := range cleanup {
+            if _, err := db.Exec(query); err != nil {
+                t.Fatalf("clean storage: %v", err)
+            }
+        }
+    }
+
+    clean()
+    t.Cleanup(func() {
+        clean()
+        db.Close()
+    })
+
+    return repositories{
+        lobbies:        repository.NewLobby(db, dialect),
+        channels:       repository.NewChannel(db, dialect),
+        channelMembers: repository.NewChannelMembers(db, dialect),
+    }
+}
+
+func runContract(t *testing.T, contract func(t *testing.T, repos repositories)) {
+    for _, backend := range backends() {
+        t.Run(backend.name, func(t *testing.T) {
+            contract(t, backend.open(t))
+        })
+    }
+}
+
+func TestLobbyContract(t *testing.T) {
+    runContract(t, func(t *testing.T, repos repositories) {
+        lobby := model.Lobby{
+            Id:         "lobby",
+            CategoryID: "category",
+            GuildID:    "guild",
+            Template:   sql.NullString{Valid: true, String: "Room %owner%"},
+            Capacity:   sql.NullInt32{Valid: true, Int32: 5},
+        }
+
+        created, err := repos.lobbies.SetLobby(&lobby)
+        if err != nil || created != 1 {
+            t.Fatalf("SetLobby() = %d, %v, want 1, nil", created, err)
+        }
+
+        // A second set hits the conflict and keeps the stored lobby
+        conflicting := lobby
+        conflicting.Template = sql.NullString{Valid: true, String: "Other"}
+        created, err = repos.lobbies.SetLobby(&conflicting)
+        if err != nil || created != 0 {
+            t.Fatalf("SetLobby() on conflict = %d, %v, want 0, nil", created, err)
+        }
+
+        stored, err := repos.lobbies.GetLobby(lobby.Id, lobby.GuildID)
+        if err != nil {
+            t.Fatalf("GetLobby() error = %v", err)
+        }
+        if stored.Template != lobby.Template || stored.CategoryID != lobby.CategoryID {
+            t.Fatalf("GetLobby() = %+v, want the first set lobby", stored)
+        }
+
+        // Upsert changes the settings it carries and keeps the rest
+        if err := repos.lobbies.UpsertLobby(&model.Lobby{
+            Id:      lobby.Id,
+            Bitrate: sql.NullInt32{Valid: true, Int32: 64000},
+            NSFW:    sql.NullBool{Valid: true, Bool: false},
+        }); err != nil {
+            t.Fatalf("UpsertLobby() error = %v", err)
+        }
+        if err := repos.lobbies.UpsertLobby(&model.Lobby{
+            Id:       lobby.Id,
+            Capacity: sql.NullInt32{Valid: true, Int32: 10},
+        }); err != nil {
+            t.Fatalf("UpsertLobby() error = %v", err)
+        }
+
+        stored, err = repos.lobbies.GetLobby(lobby.Id, lobby.GuildID)
+        if err != nil {
+            t.Fatalf("GetLobby() error = %v", err)
+        }
+
+        want := lobby
+        want.Capacity = sql.NullInt32{Valid: true, Int32: 10}
+        want.Bitrate = sql.NullInt32{Valid: true, Int32: 64000}
+        want.NSFW = sql.NullBool{Valid: true, Bool: false}
+        if !reflect.DeepEqual(stored, want) {
+            t.Fatalf("GetLobby() after upsert = %+v, want %+v", stored, want)
+        }
+
+        if _, err := repos.lobbies.GetLobby(lobby.Id, "other"); !errors.Is(err, sql.ErrNoRows) {
+            t.Fatalf("GetLobby() of another guild error = %v, want sql.ErrNoRows", err)
+        }
+
+        other := model.Lobby{Id: "other", CategoryID: "category", GuildID: "other"}
+        if _, err := repos.lobbies.SetLobby(&other); err != nil {
+            t.Fatalf("SetLobby() error = %v", err)
+        }
+
+        lobbies, err := repos.lobbies.GetLobbies(lobby.GuildID)
+        if err != nil || len(lobbies) != 1 || lobbies[0].Id != lobby.Id {
+            t.Fatalf("GetLobbies() = %+v, %v, want only lobby[%s]", lobbies, err, lobby.Id)
+        }
+
+        deleted, err := repos.lobbies.DeleteLobby(lobby.Id, "other")
+        if err != nil || deleted != 0 {
+            t.Fatalf("DeleteLobby() of another guild = %d, %v, want 0, nil", deleted, err)
+        }
+
+        deleted, err = repos.lobbies.DeleteLobby(lobby.Id, lobby.GuildID)
+        if err != nil || deleted != 1 {
+            t.Fatalf("DeleteLobby() = %d, %v, want 1, nil", deleted, err)
+        }
+
+        deleted, err = repos.lobbies.DeleteLobby(lobby.Id, lobby.GuildID)
+        if err != nil || deleted != 0 {
+            t.Fatalf("DeleteLobby() twice = %d, %v, want 0, nil", deleted, err)
+        }
+
+        if _, err := repos.lobbies.GetLobby(lobby.Id, lobby.GuildID); !errors.Is(err, sql.ErrNoRows) {
+            t.Fatalf("GetLobby() after delete error = %v, want sql.ErrNoRows", err)
+        }
+    })
+}
+
+func TestChannelContract(t *testing.T) {
+    runContract(t, func(t *testing.T, repos repositories) {
+        channel := model.Channel{
+            Id:       "room",
+            ParentID: "lobby",
+            GuildID:  "guild",
+            OwnerID:  "owner",
+            Privacy:  model.PrivacyOpen,
+            Index:    2,
+        }
+        if err := repos.channels.SetChannel(&channel); err != nil {
+            t.Fatalf("SetChannel() error = %v", err)
+        }
+
+        if err := repos.channels.SetChannelDeleteAt(channel.Id, 1700000000); err != nil {
+            t.Fatalf("SetChannelDeleteAt() error = %v", err)
+        }
+
+        stored, err := repos.channels.GetChannel(channel.Id, channel.GuildID)
+        if err != nil || stored.DeleteAt != 1700000000 {
+            t.Fatalf("GetChannel() = %+v, %v, want pending deletion", stored, err)
+        }
+
+        // Setting the channel again replaces it and clears the pending deletion
+        replaced := channel
+        replaced.OwnerID = "heir"
+        replaced.Privacy = model.PrivacyLocked
+        replaced.TextID = "text"
+        if err := repos.channels.SetChannel(&replaced); err != nil {
+            t.Fatalf("SetChannel() replace error = %v", err)
+        }
+
+        stored, err = repos.channels.GetChannel(channel.Id, channel.GuildID)
+        if err != nil || stored != replaced {
+            t.Fatalf("GetChannel() after replace = %+v, %v, want %+v", stored, err, replaced)
+        }
+
+        if _, err := repos.channels.GetChannel(channel.Id, "other"); !errors.Is(err, sql.ErrNoRows) {
+            t.Fatalf("GetChannel() of another guild error = %v, want sql.ErrNoRows", err)
+        }
+
+        if err := repos.channels.SetChannelOwner(channel.Id, "owner"); err != nil {
+            t.Fatalf("SetChannelOwner() error = %v", err)
+        }
+        if err := repos.channels.SetChannelPrivacy(channel.Id, model.PrivacyHidden); err != nil {
+            t.Fatalf("SetChannelPrivacy() error = %v", err)
+        }
+        if err := repos.channels.SetChannelDeleteAt(channel.Id, 1700000000); err != nil {
+            t.Fatalf("SetChannelDeleteAt() error = %v", err)
+        }
+        if err := repos.channels.SetChannelDeleteAt(channel.Id, 0); err != nil {
+            t.Fatalf("SetChannelDeleteAt() clear error = %v", err)
+        }
+
+        stored, err = repos.channels.GetChannel(channel.Id, channel.GuildID)
+        if err != nil || stored.OwnerID != "owner" || stored.Privacy != model.PrivacyHidden || stored.DeleteAt != 0 {
+            t.Fatalf("GetChannel() after updates = %+v, %v", stored, err)
+        }
+
+        for _, other := range []model.Channel{
+            {Id: "first", ParentID: "lobby", GuildID: "guild", Index: 1},
+            {Id: "third", ParentID: "lobby", GuildID: "guild", Index: 3},
+            {Id: "elsewhere", ParentID: "other", GuildID: "other", Index: 1},
+        } {
+            if err := repos.channels.SetChannel(&other); err != nil {
+                t.Fatalf("SetChannel() error = %v", err)
+            }
+        }
+
+        // Another channel cannot take an index of the lobby, the holder stays untouched
+        taken := model.Channel{Id: "taken", ParentID: "lobby", GuildID: "guild", Index: 3}
+        if err := repos.channels.SetChannel(&taken); err == nil {
+            t.Fatalf("SetChannel() of a taken index error = nil, want a conflict")
+        }
+
+        if _, err := repos.channels.GetChannel("third", "guild"); err != nil {
+            t.Fatalf("GetChannel() of the index holder error = %v", err)
+        }
+
+        indexes, err := repos.channels.GetChannelIndexes("lobby")
+        if err != nil || !reflect.DeepEqual(indexes, []int{1, 2, 3}) {
+            t.Fatalf("GetChannelIndexes() = %v, %v, want [1 2 3]", indexes, err)
+        }
+
+        channels, err := repos.channels.GetChannels("guild")
+        if err != nil || len(channels) != 3 {
+            t.Fatalf("GetChannels() = %+v, %v, want 3 channels of the guild", channels, err)
+        }
+
+        if err := repos.channels.DeleteChannel(channel.Id); err != nil {
+            t.Fatalf("DeleteChannel() error = %v", err)
+        }
+
+        if _, err := repos.channels.GetChannel(channel.Id, channel.GuildID); !errors.Is(err, sql.ErrNoRows) {
+            t.Fatalf("GetChannel() after delete error = %v, want sql.ErrNoRows", err)
+        }
+
+        indexes, err = repos.channels.GetChannelIndexes("lobby")
+        if err != nil || !reflect.DeepEqual(indexes, []int{1, 3}) {
+            t.Fatalf("GetChannelIndexes() after delete = %v, %v, want [1 3]", indexes, err)
+        }
+    })
+}
+
+func TestChannelMembersContract(t *testing.T) {
+    runContract(t, func(t *testing.T, repos repositories) {
+        members := repos.channelMembers
+
+        for _, userId := range []string{"alice", "bob", "carol"} {
+            if err := members.SetChannelMember("guild", userId, "room"); err != nil {
+                t.Fatalf("SetChannelMember() error = %v", err)
+            }
+        }
+
+        // The same user is tracked separately on another guild
+        if err := members.SetChannelMember("other", "alice", "elsewhere"); err != nil {
+            t.Fatalf("SetChannelMember() error = %v", err)
+        }
+
+        assertMembers(t, members, "guild", "room", []string{"alice", "bob", "carol"})
+
+        // Joining another room moves the member, rejoining puts them last
+        if err := members.SetChannelMember("guild", "alice", "next"); err != nil {
+            t.Fatalf("SetChannelMember() move error = %v", err)
+        }
+        if err := members.SetChannelMember("guild", "alice", "room"); err != nil {
+            t.Fatalf("SetChannelMember() rejoin error = %v", err)
+        }
+
+        assertMembers(t, members, "guild", "room", []string{"bob", "carol", "alice"})
+        assertMembers(t, members, "guild", "next", nil)
+        assertMembers(t, members, "other", "elsewhere", []string{"alice"})
+
+        // Only the membership of the given room is removed
+        if err := members.DeleteChannelMember("guild", "bob", "next"); err != nil {
+            t.Fatalf("DeleteChannelMember() error = %v", err)
+        }
+        assertMembers(t, members, "guild", "room", []string{"bob", "carol", "alice"})
+
+        if err := members.DeleteChannelMember("guild", "bob", "room"); err != nil {
+            t.Fatalf("DeleteChannelMember() error = %v", err)
+        }
+        assertMembers(t, members, "guild", "room", []string{"carol", "alice"})
+
+        if err := members.DeleteChannelMembers("other", "room"); err != nil {
+            t.Fatalf("DeleteChannelMembers() error = %v", err)
+        }
+        assertMembers(t, members, "guild", "room", []string{"carol", "alice"})
+
+        if err := members.DeleteChannelMembers("guild", "room"); err != nil {
+            t.Fatalf("DeleteChannelMembers() error = %v", err)
+        }
+        assertMembers(t, members, "guild", "room", nil)
+        assertMembers(t, members, "other", "elsewhere", []string{"alice"})
+
+        if err := members.SetChannelMember("guild", "dave", "room"); err != nil {
+            t.Fatalf("SetChannelMember() error = %v", err)
+        }
+        if err := members.DeleteGuildChannelMembers("guild"); err != nil {
+            t.Fatalf("DeleteGuildChannelMembers() error = %v", err)
+        }
+        assertMembers(t, members, "guild", "room", nil)
+        assertMembers(t, members, "other", "elsewhere", []string{"alice"})
+    })
+}
+
+func assertMembers(
+    t *testing.T,
+    members repository.ChannelMembersRepository,
+    guildId string,
+    channelId string,
+    want []string,
+) {
+    t.Helper()
+
+    got, err := members.GetChannelMembers(guildId, channelId)
+    if err != nil || len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+        t.Fatalf("GetChannelMembers(%s, %s) = %v, %v, want %v", guildId, channelId, got, err, want)
+    }
+
+    count, err := members.GetChannelMembersCount(guildId, channelId)
+    if err != nil || count != len(want) {
+        t.Fatalf("GetChannelMembersCount(%s, %s) = %d, %v, want %d", guildId, channelId, count, err, len(want))
+    }
+}
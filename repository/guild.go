@@ -0,0 +1,54 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLGuildRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewGuild(db *sql.DB, dialect storage.Dialect) *SQLGuildRepository {
+    return &SQLGuildRepository{db: db, dialect: dialect}
+}
+
+const SelectGuildById = `
+SELECT id, max_rooms
+FROM guilds
+WHERE id = ?
+`
+
+func (gr *SQLGuildRepository) GetGuild(id string) (model.Guild, error) {
+    log.Debug().Printf("repo: get guild[%s]", id)
+
+    var guild model.Guild
+    if err := gr.db.QueryRow(gr.dialect.Query(SelectGuildById), id).Scan(&guild.Id, &guild.MaxRooms); err != nil {
+        return model.Guild{}, fmt.Errorf("repo: unable to get guild[%s]: %w", id, err)
+    }
+
+    return guild, nil
+}
+
+const UpsertGuild = `
+INSERT INTO guilds (id, max_rooms)
+VALUES(?, ?)
+ON CONFLICT(id)
+DO UPDATE
+SET
+	max_rooms = coalesce(EXCLUDED.max_rooms, guilds.max_rooms)
+`
+
+func (gr *SQLGuildRepository) UpsertGuild(guild *model.Guild) error {
+    log.Debug().Printf("repo: upsert guild[%s]", guild.Id)
+
+    if _, err := gr.db.Exec(gr.dialect.Query(UpsertGuild), guild.Id, guild.MaxRooms); err != nil {
+        return fmt.Errorf("repo: unable to upsert guild[%s]: %w", guild.Id, err)
+    }
+
+    return nil
+}
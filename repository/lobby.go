@@ -5,28 +5,32 @@ import (
     "fmt"
     "hometown-bot/log"
     "hometown-bot/model"
+    "hometown-bot/storage"
 )
 
-type LobbyRepository struct {
-    db *sql.DB
+type SQLLobbyRepository struct {
+    db      querier
+    dialect storage.Dialect
 }
 
-func NewLobby(db *sql.DB) *LobbyRepository {
-    return &LobbyRepository{db: db}
+func NewLobby(db *sql.DB, dialect storage.Dialect) *SQLLobbyRepository {
+    return &SQLLobbyRepository{db: db, dialect: dialect}
 }
 
 const SelectLobbyById = `
-SELECT id, template, capacity, category_id, guild_id
+SELECT id, template, capacity, category_id, guild_id, naming, grace_period, bitrate, rtc_region, video_quality, nsfw,
+    text_channel, activity, overrides, cooldown, burst_limit, block_spam, max_rooms, waiting_id, permissions, placement,
+    auto_overflow
 FROM lobbies
 WHERE (id = ? AND guild_id = ?)
 `
 
-func (cr *LobbyRepository) GetLobby(id string, guildId string) (model.Lobby, error) {
+func (cr *SQLLobbyRepository) GetLobby(id string, guildId string) (model.Lobby, error) {
     log.Debug().Printf("repo: get lobby[%s] for guild[%s]", id, guildId)
 
     var lobby model.Lobby
     if err := cr.db.QueryRow(
-        SelectLobbyById,
+        cr.dialect.Query(SelectLobbyById),
         id,
         guildId,
     ).Scan(
@@ -35,6 +39,23 @@ func (cr *LobbyRepository) GetLobby(id string, guildId string) (model.Lobby, err
         &lobby.Capacity,
         &lobby.CategoryID,
         &lobby.GuildID,
+        &lobby.Naming,
+        &lobby.Grace,
+        &lobby.Bitrate,
+        &lobby.RTCRegion,
+        &lobby.VideoQuality,
+        &lobby.NSFW,
+        &lobby.TextChannel,
+        &lobby.Activity,
+        &lobby.Overrides,
+        &lobby.Cooldown,
+        &lobby.BurstLimit,
+        &lobby.BlockSpam,
+        &lobby.MaxRooms,
+        &lobby.WaitingID,
+        &lobby.Permissions,
+        &lobby.Placement,
+        &lobby.AutoOverflow,
     ); err != nil {
         return model.Lobby{}, fmt.Errorf(
             "repo: unable to get lobby[%s] for guild[%s]: %w",
@@ -48,15 +69,17 @@ func (cr *LobbyRepository) GetLobby(id string, guildId string) (model.Lobby, err
 }
 
 const SelectLobbies = `
-SELECT id, template, capacity, category_id, guild_id
+SELECT id, template, capacity, category_id, guild_id, naming, grace_period, bitrate, rtc_region, video_quality, nsfw,
+    text_channel, activity, overrides, cooldown, burst_limit, block_spam, max_rooms, waiting_id, permissions, placement,
+    auto_overflow
 FROM lobbies
 WHERE guild_id = ?
 `
 
-func (cr *LobbyRepository) GetLobbies(guildId string) ([]model.Lobby, error) {
+func (cr *SQLLobbyRepository) GetLobbies(guildId string) ([]model.Lobby, error) {
     log.Debug().Printf("repo: get lobbies for guild[%s]", guildId)
 
-    rows, err := cr.db.Query(SelectLobbies, guildId)
+    rows, err := cr.db.Query(cr.dialect.Query(SelectLobbies), guildId)
     if err != nil {
         return []model.Lobby{}, fmt.Errorf(
             "repo: unable to lobbies for guild[%s]: %w",
@@ -64,6 +87,7 @@ func (cr *LobbyRepository) GetLobbies(guildId string) ([]model.Lobby, error) {
             err,
         )
     }
+    defer rows.Close()
 
     var lobbies []model.Lobby
     for rows.Next() {
@@ -75,6 +99,23 @@ func (cr *LobbyRepository) GetLobbies(guildId string) ([]model.Lobby, error) {
             &lobby.Capacity,
             &lobby.CategoryID,
             &lobby.GuildID,
+            &lobby.Naming,
+            &lobby.Grace,
+            &lobby.Bitrate,
+            &lobby.RTCRegion,
+            &lobby.VideoQuality,
+            &lobby.NSFW,
+            &lobby.TextChannel,
+            &lobby.Activity,
+            &lobby.Overrides,
+            &lobby.Cooldown,
+            &lobby.BurstLimit,
+            &lobby.BlockSpam,
+            &lobby.MaxRooms,
+            &lobby.WaitingID,
+            &lobby.Permissions,
+            &lobby.Placement,
+            &lobby.AutoOverflow,
         ); err != nil {
             return nil, fmt.Errorf(
                 "repo: unable to lobbies for guild[%s]: %w",
@@ -97,19 +138,30 @@ WHERE (id = ? AND guild_id = ?)
 DO NOTHING
 `
 
-func (cr *LobbyRepository) SetLobby(lobby *model.Lobby) (int64, error) {
+// ReplaceLobbyPostgres takes no conflict predicate, unlike ReplaceLobby
+const ReplaceLobbyPostgres = `
+INSERT INTO lobbies (id, template, capacity, category_id, guild_id)
+VALUES(?, ?, ?, ?, ?)
+ON CONFLICT(id)
+DO NOTHING
+`
+
+func (cr *SQLLobbyRepository) SetLobby(lobby *model.Lobby) (int64, error) {
     log.Debug().Printf("repo: set lobby[%s]", lobby.Id)
 
-    result, err := cr.db.Exec(
-        ReplaceLobby,
+    args := []any{
         lobby.Id,
         lobby.Template,
         lobby.Capacity,
         lobby.CategoryID,
         lobby.GuildID,
-        lobby.Id,
-        lobby.GuildID,
-    )
+    }
+
+    if cr.dialect == storage.DialectSQLite {
+        args = append(args, lobby.Id, lobby.GuildID)
+    }
+
+    result, err := cr.db.Exec(cr.dialect.Pick(ReplaceLobby, ReplaceLobbyPostgres), args...)
     if err != nil {
         return 0, fmt.Errorf("repo: unable to set lobby[%s]: %w", lobby.Id, err)
     }
@@ -123,23 +175,86 @@ func (cr *LobbyRepository) SetLobby(lobby *model.Lobby) (int64, error) {
 }
 
 const UpsertLobby = `
-INSERT INTO lobbies (id, template, capacity)
-VALUES(?, ?, ?)
+INSERT INTO lobbies (id, template, capacity, naming, grace_period, bitrate, rtc_region, video_quality, nsfw, text_channel, activity,
+    overrides, cooldown, burst_limit, block_spam, max_rooms, waiting_id, permissions, placement, auto_overflow)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(id) 
 DO UPDATE
 SET
 	template = coalesce(EXCLUDED.template, template),
-	capacity = coalesce(EXCLUDED.capacity, capacity)
+	capacity = coalesce(EXCLUDED.capacity, capacity),
+	naming = coalesce(EXCLUDED.naming, naming),
+	grace_period = coalesce(EXCLUDED.grace_period, grace_period),
+	bitrate = coalesce(EXCLUDED.bitrate, bitrate),
+	rtc_region = coalesce(EXCLUDED.rtc_region, rtc_region),
+	video_quality = coalesce(EXCLUDED.video_quality, video_quality),
+	nsfw = coalesce(EXCLUDED.nsfw, nsfw),
+	text_channel = coalesce(EXCLUDED.text_channel, text_channel),
+	activity = coalesce(EXCLUDED.activity, activity),
+	overrides = coalesce(EXCLUDED.overrides, overrides),
+	cooldown = coalesce(EXCLUDED.cooldown, cooldown),
+	burst_limit = coalesce(EXCLUDED.burst_limit, burst_limit),
+	block_spam = coalesce(EXCLUDED.block_spam, block_spam),
+	max_rooms = coalesce(EXCLUDED.max_rooms, max_rooms),
+	waiting_id = coalesce(EXCLUDED.waiting_id, waiting_id),
+	permissions = coalesce(EXCLUDED.permissions, permissions),
+	placement = coalesce(EXCLUDED.placement, placement),
+	auto_overflow = coalesce(EXCLUDED.auto_overflow, auto_overflow)
+`
+
+const UpsertLobbyPostgres = `
+INSERT INTO lobbies (id, template, capacity, naming, grace_period, bitrate, rtc_region, video_quality, nsfw, text_channel, activity,
+    overrides, cooldown, burst_limit, block_spam, max_rooms, waiting_id, permissions, placement, auto_overflow)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id)
+DO UPDATE
+SET
+	template = coalesce(EXCLUDED.template, lobbies.template),
+	capacity = coalesce(EXCLUDED.capacity, lobbies.capacity),
+	naming = coalesce(EXCLUDED.naming, lobbies.naming),
+	grace_period = coalesce(EXCLUDED.grace_period, lobbies.grace_period),
+	bitrate = coalesce(EXCLUDED.bitrate, lobbies.bitrate),
+	rtc_region = coalesce(EXCLUDED.rtc_region, lobbies.rtc_region),
+	video_quality = coalesce(EXCLUDED.video_quality, lobbies.video_quality),
+	nsfw = coalesce(EXCLUDED.nsfw, lobbies.nsfw),
+	text_channel = coalesce(EXCLUDED.text_channel, lobbies.text_channel),
+	activity = coalesce(EXCLUDED.activity, lobbies.activity),
+	overrides = coalesce(EXCLUDED.overrides, lobbies.overrides),
+	cooldown = coalesce(EXCLUDED.cooldown, lobbies.cooldown),
+	burst_limit = coalesce(EXCLUDED.burst_limit, lobbies.burst_limit),
+	block_spam = coalesce(EXCLUDED.block_spam, lobbies.block_spam),
+	max_rooms = coalesce(EXCLUDED.max_rooms, lobbies.max_rooms),
+	waiting_id = coalesce(EXCLUDED.waiting_id, lobbies.waiting_id),
+	permissions = coalesce(EXCLUDED.permissions, lobbies.permissions),
+	placement = coalesce(EXCLUDED.placement, lobbies.placement),
+	auto_overflow = coalesce(EXCLUDED.auto_overflow, lobbies.auto_overflow)
 `
 
-func (cr *LobbyRepository) UpsertLobby(lobby *model.Lobby) error {
+func (cr *SQLLobbyRepository) UpsertLobby(lobby *model.Lobby) error {
     log.Debug().Printf("repo: upsert lobby[%s]", lobby.Id)
 
     if _, err := cr.db.Exec(
-        UpsertLobby,
+        cr.dialect.Pick(UpsertLobby, UpsertLobbyPostgres),
         lobby.Id,
         lobby.Template,
         lobby.Capacity,
+        lobby.Naming,
+        lobby.Grace,
+        lobby.Bitrate,
+        lobby.RTCRegion,
+        lobby.VideoQuality,
+        lobby.NSFW,
+        lobby.TextChannel,
+        lobby.Activity,
+        lobby.Overrides,
+        lobby.Cooldown,
+        lobby.BurstLimit,
+        lobby.BlockSpam,
+        lobby.MaxRooms,
+        lobby.WaitingID,
+        lobby.Permissions,
+        lobby.Placement,
+        lobby.AutoOverflow,
     ); err != nil {
         return fmt.Errorf("repo: unable to upsert lobby[%s]: %w", lobby.Id, err)
     }
@@ -152,10 +267,10 @@ DELETE FROM lobbies
 WHERE (id = ? AND guild_id = ?)
 `
 
-func (cr *LobbyRepository) DeleteLobby(id string, guildId string) (int64, error) {
+func (cr *SQLLobbyRepository) DeleteLobby(id string, guildId string) (int64, error) {
     log.Debug().Printf("repo: delete lobby[%s] for guild[%s]", id, guildId)
 
-    result, err := cr.db.Exec(DeleteLobby, id, guildId)
+    result, err := cr.db.Exec(cr.dialect.Query(DeleteLobby), id, guildId)
     if err != nil {
         return 0, fmt.Errorf("repo: unable to delete lobby[%s] for guild[%s]: %w", id, guildId, err)
     }
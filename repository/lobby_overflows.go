@@ -0,0 +1,107 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLLobbyOverflowsRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewLobbyOverflows(db *sql.DB, dialect storage.Dialect) *SQLLobbyOverflowsRepository {
+    return &SQLLobbyOverflowsRepository{db: db, dialect: dialect}
+}
+
+const SelectLobbyOverflows = `
+SELECT lobby_id, category_id, position
+FROM lobby_overflows
+WHERE lobby_id = ?
+ORDER BY position
+`
+
+func (lor *SQLLobbyOverflowsRepository) GetLobbyOverflows(lobbyId string) ([]model.LobbyOverflow, error) {
+    log.Debug().Printf("repo: get lobby[%s] overflows", lobbyId)
+
+    rows, err := lor.db.Query(lor.dialect.Query(SelectLobbyOverflows), lobbyId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get lobby[%s] overflows: %w", lobbyId, err)
+    }
+    defer rows.Close()
+
+    var overflows []model.LobbyOverflow
+    for rows.Next() {
+        var overflow model.LobbyOverflow
+
+        if err := rows.Scan(&overflow.LobbyID, &overflow.CategoryID, &overflow.Position); err != nil {
+            return nil, fmt.Errorf("repo: unable to get lobby[%s] overflows: %w", lobbyId, err)
+        }
+
+        overflows = append(overflows, overflow)
+    }
+
+    return overflows, nil
+}
+
+const InsertLobbyOverflow = `
+INSERT INTO lobby_overflows (lobby_id, category_id, position)
+VALUES(?, ?, (SELECT coalesce(max(position), 0) + 1 FROM lobby_overflows WHERE lobby_id = ?))
+ON CONFLICT DO NOTHING
+`
+
+// AddLobbyOverflow appends the category to the end of the lobby overflow list.
+func (lor *SQLLobbyOverflowsRepository) AddLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    log.Debug().Printf("repo: add lobby[%s] overflow category[%s]", lobbyId, categoryId)
+
+    result, err := lor.db.Exec(lor.dialect.Query(InsertLobbyOverflow), lobbyId, categoryId, lobbyId)
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to add lobby[%s] overflow category[%s]: %w", lobbyId, categoryId, err)
+    }
+
+    affectedRows, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to add lobby[%s] overflow category[%s]: %w", lobbyId, categoryId, err)
+    }
+
+    return affectedRows, nil
+}
+
+const DeleteLobbyOverflow = `
+DELETE FROM lobby_overflows
+WHERE (lobby_id = ? AND category_id = ?)
+`
+
+func (lor *SQLLobbyOverflowsRepository) DeleteLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    log.Debug().Printf("repo: delete lobby[%s] overflow category[%s]", lobbyId, categoryId)
+
+    result, err := lor.db.Exec(lor.dialect.Query(DeleteLobbyOverflow), lobbyId, categoryId)
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete lobby[%s] overflow category[%s]: %w", lobbyId, categoryId, err)
+    }
+
+    affectedRows, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete lobby[%s] overflow category[%s]: %w", lobbyId, categoryId, err)
+    }
+
+    return affectedRows, nil
+}
+
+const DeleteLobbyOverflows = `
+DELETE FROM lobby_overflows
+WHERE lobby_id = ?
+`
+
+func (lor *SQLLobbyOverflowsRepository) DeleteLobbyOverflows(lobbyId string) error {
+    log.Debug().Printf("repo: delete lobby[%s] overflows", lobbyId)
+
+    if _, err := lor.db.Exec(lor.dialect.Query(DeleteLobbyOverflows), lobbyId); err != nil {
+        return fmt.Errorf("repo: unable to delete lobby[%s] overflows: %w", lobbyId, err)
+    }
+
+    return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLLobbyOverwritesRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewLobbyOverwrites(db *sql.DB, dialect storage.Dialect) *SQLLobbyOverwritesRepository {
+    return &SQLLobbyOverwritesRepository{db: db, dialect: dialect}
+}
+
+const SelectLobbyOverwrites = `
+SELECT lobby_id, target_id, type, allow, deny
+FROM lobby_overwrites
+WHERE lobby_id = ?
+`
+
+func (lor *SQLLobbyOverwritesRepository) GetLobbyOverwrites(lobbyId string) ([]model.LobbyOverwrite, error) {
+    log.Debug().Printf("repo: get lobby[%s] overwrites", lobbyId)
+
+    rows, err := lor.db.Query(lor.dialect.Query(SelectLobbyOverwrites), lobbyId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get lobby[%s] overwrites: %w", lobbyId, err)
+    }
+    defer rows.Close()
+
+    var overwrites []model.LobbyOverwrite
+    for rows.Next() {
+        var overwrite model.LobbyOverwrite
+
+        if err := rows.Scan(
+            &overwrite.LobbyID,
+            &overwrite.TargetID,
+            &overwrite.Type,
+            &overwrite.Allow,
+            &overwrite.Deny,
+        ); err != nil {
+            return nil, fmt.Errorf("repo: unable to get lobby[%s] overwrites: %w", lobbyId, err)
+        }
+
+        overwrites = append(overwrites, overwrite)
+    }
+
+    return overwrites, nil
+}
+
+const InsertLobbyOverwrite = `
+INSERT INTO lobby_overwrites (lobby_id, target_id, type, allow, deny)
+VALUES(?, ?, ?, ?, ?)
+ON CONFLICT(lobby_id, target_id)
+DO UPDATE
+SET
+	type = EXCLUDED.type,
+	allow = EXCLUDED.allow,
+	deny = EXCLUDED.deny
+`
+
+func (lor *SQLLobbyOverwritesRepository) SetLobbyOverwrite(overwrite *model.LobbyOverwrite) error {
+    log.Debug().Printf("repo: set lobby[%s] overwrite for target[%s]", overwrite.LobbyID, overwrite.TargetID)
+
+    if _, err := lor.db.Exec(
+        lor.dialect.Query(InsertLobbyOverwrite),
+        overwrite.LobbyID,
+        overwrite.TargetID,
+        overwrite.Type,
+        overwrite.Allow,
+        overwrite.Deny,
+    ); err != nil {
+        return fmt.Errorf("repo: unable to set lobby[%s] overwrite for target[%s]: %w", overwrite.LobbyID, overwrite.TargetID, err)
+    }
+
+    return nil
+}
+
+const DeleteLobbyOverwrite = `
+DELETE FROM lobby_overwrites
+WHERE (lobby_id = ? AND target_id = ?)
+`
+
+func (lor *SQLLobbyOverwritesRepository) DeleteLobbyOverwrite(lobbyId string, targetId string) error {
+    log.Debug().Printf("repo: delete lobby[%s] overwrite for target[%s]", lobbyId, targetId)
+
+    if _, err := lor.db.Exec(lor.dialect.Query(DeleteLobbyOverwrite), lobbyId, targetId); err != nil {
+        return fmt.Errorf("repo: unable to delete lobby[%s] overwrite for target[%s]: %w", lobbyId, targetId, err)
+    }
+
+    return nil
+}
+
+const DeleteLobbyOverwrites = `
+DELETE FROM lobby_overwrites
+WHERE lobby_id = ?
+`
+
+func (lor *SQLLobbyOverwritesRepository) DeleteLobbyOverwrites(lobbyId string) error {
+    log.Debug().Printf("repo: delete lobby[%s] overwrites", lobbyId)
+
+    if _, err := lor.db.Exec(lor.dialect.Query(DeleteLobbyOverwrites), lobbyId); err != nil {
+        return fmt.Errorf("repo: unable to delete lobby[%s] overwrites: %w", lobbyId, err)
+    }
+
+    return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLLobbyRolesRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewLobbyRoles(db *sql.DB, dialect storage.Dialect) *SQLLobbyRolesRepository {
+    return &SQLLobbyRolesRepository{db: db, dialect: dialect}
+}
+
+const SelectLobbyRoles = `
+SELECT lobby_id, role_id, allowed
+FROM lobby_roles
+WHERE lobby_id = ?
+`
+
+func (lrr *SQLLobbyRolesRepository) GetLobbyRoles(lobbyId string) ([]model.LobbyRole, error) {
+    log.Debug().Printf("repo: get lobby[%s] roles", lobbyId)
+
+    rows, err := lrr.db.Query(lrr.dialect.Query(SelectLobbyRoles), lobbyId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get lobby[%s] roles: %w", lobbyId, err)
+    }
+    defer rows.Close()
+
+    var roles []model.LobbyRole
+    for rows.Next() {
+        var role model.LobbyRole
+
+        if err := rows.Scan(&role.LobbyID, &role.RoleID, &role.Allowed); err != nil {
+            return nil, fmt.Errorf("repo: unable to get lobby[%s] roles: %w", lobbyId, err)
+        }
+
+        roles = append(roles, role)
+    }
+
+    return roles, nil
+}
+
+const InsertLobbyRole = `
+INSERT INTO lobby_roles (lobby_id, role_id, allowed)
+VALUES(?, ?, ?)
+ON CONFLICT(lobby_id, role_id)
+DO UPDATE
+SET
+	allowed = EXCLUDED.allowed
+`
+
+func (lrr *SQLLobbyRolesRepository) SetLobbyRole(role *model.LobbyRole) error {
+    log.Debug().Printf("repo: set lobby[%s] role[%s]", role.LobbyID, role.RoleID)
+
+    if _, err := lrr.db.Exec(lrr.dialect.Query(InsertLobbyRole), role.LobbyID, role.RoleID, role.Allowed); err != nil {
+        return fmt.Errorf("repo: unable to set lobby[%s] role[%s]: %w", role.LobbyID, role.RoleID, err)
+    }
+
+    return nil
+}
+
+const DeleteLobbyRole = `
+DELETE FROM lobby_roles
+WHERE (lobby_id = ? AND role_id = ?)
+`
+
+func (lrr *SQLLobbyRolesRepository) DeleteLobbyRole(lobbyId string, roleId string) (int64, error) {
+    log.Debug().Printf("repo: delete lobby[%s] role[%s]", lobbyId, roleId)
+
+    result, err := lrr.db.Exec(lrr.dialect.Query(DeleteLobbyRole), lobbyId, roleId)
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete lobby[%s] role[%s]: %w", lobbyId, roleId, err)
+    }
+
+    affectedRows, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete lobby[%s] role[%s]: %w", lobbyId, roleId, err)
+    }
+
+    return affectedRows, nil
+}
+
+const DeleteLobbyRoles = `
+DELETE FROM lobby_roles
+WHERE lobby_id = ?
+`
+
+func (lrr *SQLLobbyRolesRepository) DeleteLobbyRoles(lobbyId string) error {
+    log.Debug().Printf("repo: delete lobby[%s] roles", lobbyId)
+
+    if _, err := lrr.db.Exec(lrr.dialect.Query(DeleteLobbyRoles), lobbyId); err != nil {
+        return fmt.Errorf("repo: unable to delete lobby[%s] roles: %w", lobbyId, err)
+    }
+
+    return nil
+}
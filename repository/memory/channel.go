@@ -0,0 +1,150 @@
+package memory
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/model"
+    "sort"
+    "sync"
+)
+
+type ChannelRepository struct {
+    mu       sync.RWMutex
+    channels map[string]model.Channel
+}
+
+func NewChannel() *ChannelRepository {
+    return &ChannelRepository{channels: make(map[string]model.Channel)}
+}
+
+func (cr *ChannelRepository) GetChannel(id string, guildId string) (model.Channel, error) {
+    cr.mu.RLock()
+    defer cr.mu.RUnlock()
+
+    channel, ok := cr.channels[id]
+    if !ok || channel.GuildID != guildId {
+        return model.Channel{}, fmt.Errorf("memory: unable to get channel[%s] for guild[%s]: %w", id, guildId, sql.ErrNoRows)
+    }
+
+    return channel, nil
+}
+
+func (cr *ChannelRepository) GetChannels(guildId string) ([]model.Channel, error) {
+    cr.mu.RLock()
+    defer cr.mu.RUnlock()
+
+    var channels []model.Channel
+    for _, channel := range cr.channels {
+        if channel.GuildID == guildId {
+            channels = append(channels, channel)
+        }
+    }
+
+    sort.Slice(channels, func(a, b int) bool {
+        return channels[a].Id < channels[b].Id
+    })
+
+    return channels, nil
+}
+
+func (cr *ChannelRepository) GetChannelIndexes(parentId string) ([]int, error) {
+    cr.mu.RLock()
+    defer cr.mu.RUnlock()
+
+    var indexes []int
+    for _, channel := range cr.channels {
+        if channel.ParentID == parentId {
+            indexes = append(indexes, channel.Index)
+        }
+    }
+
+    sort.Ints(indexes)
+
+    return indexes, nil
+}
+
+// SetChannel fails if another channel of the lobby holds the index, like the unique index of the SQL storage.
+func (cr *ChannelRepository) SetChannel(channel *model.Channel) error {
+    cr.mu.Lock()
+    defer cr.mu.Unlock()
+
+    for id, other := range cr.channels {
+        if id != channel.Id && other.ParentID == channel.ParentID && other.Index == channel.Index {
+            return fmt.Errorf(
+                "memory: unable to set channel[%s]: index %d of lobby[%s] is taken by channel[%s]",
+                channel.Id,
+                channel.Index,
+                channel.ParentID,
+                id,
+            )
+        }
+    }
+
+    stored := *channel
+    stored.DeleteAt = 0
+    cr.channels[channel.Id] = stored
+
+    return nil
+}
+
+func (cr *ChannelRepository) SetChannelOwner(id string, ownerId string) error {
+    return cr.update(id, func(channel *model.Channel) {
+        channel.OwnerID = ownerId
+    })
+}
+
+func (cr *ChannelRepository) SetChannelPrivacy(id string, privacy model.Privacy) error {
+    return cr.update(id, func(channel *model.Channel) {
+        channel.Privacy = privacy
+    })
+}
+
+// SetChannelDeleteAt stores unix time of the pending deletion, zero clears it.
+func (cr *ChannelRepository) SetChannelDeleteAt(id string, deleteAt int64) error {
+    return cr.update(id, func(channel *model.Channel) {
+        channel.DeleteAt = deleteAt
+    })
+}
+
+func (cr *ChannelRepository) DeleteChannel(id string) error {
+    cr.mu.Lock()
+    defer cr.mu.Unlock()
+
+    delete(cr.channels, id)
+
+    return nil
+}
+
+// update changes a stored channel, missing channels are ignored like by an SQL UPDATE.
+func (cr *ChannelRepository) update(id string, change func(channel *model.Channel)) error {
+    cr.mu.Lock()
+    defer cr.mu.Unlock()
+
+    channel, ok := cr.channels[id]
+    if !ok {
+        return nil
+    }
+
+    change(&channel)
+    cr.channels[id] = channel
+
+    return nil
+}
+
+// backup returns a function restoring the channel as it is now, including its absence.
+func (cr *ChannelRepository) backup(id string) func() {
+    cr.mu.RLock()
+    channel, ok := cr.channels[id]
+    cr.mu.RUnlock()
+
+    return func() {
+        cr.mu.Lock()
+        defer cr.mu.Unlock()
+
+        if ok {
+            cr.channels[id] = channel
+        } else {
+            delete(cr.channels, id)
+        }
+    }
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+    "sort"
+    "sync"
+    "time"
+)
+
+type memberKey struct {
+    guildId string
+    userId  string
+}
+
+type channelMember struct {
+    channelId string
+    joinedAt  int64
+}
+
+type ChannelMembersRepository struct {
+    mu      sync.RWMutex
+    members map[memberKey]channelMember // Users are in one channel per guild at a time
+}
+
+func NewChannelMembers() *ChannelMembersRepository {
+    return &ChannelMembersRepository{members: make(map[memberKey]channelMember)}
+}
+
+func (cmr *ChannelMembersRepository) GetChannelMembersCount(guildId string, channelId string) (int, error) {
+    members, err := cmr.GetChannelMembers(guildId, channelId)
+
+    return len(members), err
+}
+
+// GetChannelMembers returns members of the channel, the longest present member goes first.
+func (cmr *ChannelMembersRepository) GetChannelMembers(guildId string, channelId string) ([]string, error) {
+    cmr.mu.RLock()
+    defer cmr.mu.RUnlock()
+
+    var keys []memberKey
+    for key, member := range cmr.members {
+        if key.guildId == guildId && member.channelId == channelId {
+            keys = append(keys, key)
+        }
+    }
+
+    sort.Slice(keys, func(a, b int) bool {
+        return cmr.members[keys[a]].joinedAt < cmr.members[keys[b]].joinedAt
+    })
+
+    var members []string
+    for _, key := range keys {
+        members = append(members, key.userId)
+    }
+
+    return members, nil
+}
+
+func (cmr *ChannelMembersRepository) SetChannelMember(guildId string, userId string, channelId string) error {
+    cmr.mu.Lock()
+    defer cmr.mu.Unlock()
+
+    cmr.members[memberKey{guildId: guildId, userId: userId}] = channelMember{
+        channelId: channelId,
+        joinedAt:  time.Now().UnixNano(),
+    }
+
+    return nil
+}
+
+func (cmr *ChannelMembersRepository) DeleteChannelMember(guildId string, userId string, channelId string) error {
+    cmr.mu.Lock()
+    defer cmr.mu.Unlock()
+
+    key := memberKey{guildId: guildId, userId: userId}
+    if member, ok := cmr.members[key]; ok && member.channelId == channelId {
+        delete(cmr.members, key)
+    }
+
+    return nil
+}
+
+func (cmr *ChannelMembersRepository) DeleteChannelMembers(guildId string, channelId string) error {
+    cmr.mu.Lock()
+    defer cmr.mu.Unlock()
+
+    for key, member := range cmr.members {
+        if key.guildId == guildId && member.channelId == channelId {
+            delete(cmr.members, key)
+        }
+    }
+
+    return nil
+}
+
+func (cmr *ChannelMembersRepository) DeleteGuildChannelMembers(guildId string) error {
+    cmr.mu.Lock()
+    defer cmr.mu.Unlock()
+
+    for key := range cmr.members {
+        if key.guildId == guildId {
+            delete(cmr.members, key)
+        }
+    }
+
+    return nil
+}
+
+// backup returns a function restoring the members matched by filter as they are now.
+func (cmr *ChannelMembersRepository) backup(filter func(key memberKey, member channelMember) bool) func() {
+    cmr.mu.RLock()
+    kept := make(map[memberKey]channelMember)
+    for key, member := range cmr.members {
+        if filter(key, member) {
+            kept[key] = member
+        }
+    }
+    cmr.mu.RUnlock()
+
+    return func() {
+        cmr.mu.Lock()
+        defer cmr.mu.Unlock()
+
+        for key, member := range cmr.members {
+            if filter(key, member) {
+                delete(cmr.members, key)
+            }
+        }
+
+        for key, member := range kept {
+            cmr.members[key] = member
+        }
+    }
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "sync"
+)
+
+type ChannelPermitsRepository struct {
+    mu      sync.RWMutex
+    permits map[string][]model.ChannelPermit // Permits by channel
+}
+
+func NewChannelPermits() *ChannelPermitsRepository {
+    return &ChannelPermitsRepository{permits: make(map[string][]model.ChannelPermit)}
+}
+
+func (cpr *ChannelPermitsRepository) GetChannelPermits(channelId string) ([]model.ChannelPermit, error) {
+    cpr.mu.RLock()
+    defer cpr.mu.RUnlock()
+
+    return append([]model.ChannelPermit(nil), cpr.permits[channelId]...), nil
+}
+
+func (cpr *ChannelPermitsRepository) SetChannelPermit(permit *model.ChannelPermit) error {
+    cpr.mu.Lock()
+    defer cpr.mu.Unlock()
+
+    permits := cpr.permits[permit.ChannelID]
+    for index := range permits {
+        if permits[index].UserID == permit.UserID {
+            permits[index].Allowed = permit.Allowed
+            return nil
+        }
+    }
+
+    cpr.permits[permit.ChannelID] = append(permits, *permit)
+
+    return nil
+}
+
+func (cpr *ChannelPermitsRepository) DeleteChannelPermits(channelId string) error {
+    cpr.mu.Lock()
+    defer cpr.mu.Unlock()
+
+    delete(cpr.permits, channelId)
+
+    return nil
+}
+
+// backup returns a function restoring the permits of the channel as they are now.
+func (cpr *ChannelPermitsRepository) backup(channelId string) func() {
+    cpr.mu.RLock()
+    permits, ok := cpr.permits[channelId]
+    permits = append([]model.ChannelPermit(nil), permits...)
+    cpr.mu.RUnlock()
+
+    return func() {
+        cpr.mu.Lock()
+        defer cpr.mu.Unlock()
+
+        if ok {
+            cpr.permits[channelId] = permits
+        } else {
+            delete(cpr.permits, channelId)
+        }
+    }
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/model"
+    "sync"
+)
+
+type GuildRepository struct {
+    mu     sync.RWMutex
+    guilds map[string]model.Guild
+}
+
+func NewGuild() *GuildRepository {
+    return &GuildRepository{guilds: make(map[string]model.Guild)}
+}
+
+func (gr *GuildRepository) GetGuild(id string) (model.Guild, error) {
+    gr.mu.RLock()
+    defer gr.mu.RUnlock()
+
+    guild, ok := gr.guilds[id]
+    if !ok {
+        return model.Guild{}, fmt.Errorf("memory: unable to get guild[%s]: %w", id, sql.ErrNoRows)
+    }
+
+    return guild, nil
+}
+
+func (gr *GuildRepository) UpsertGuild(guild *model.Guild) error {
+    gr.mu.Lock()
+    defer gr.mu.Unlock()
+
+    stored, ok := gr.guilds[guild.Id]
+    if !ok || guild.MaxRooms.Valid {
+        stored = *guild
+    }
+
+    gr.guilds[guild.Id] = stored
+
+    return nil
+}
+
+// backup returns a function restoring the guild as it is now.
+func (gr *GuildRepository) backup(id string) func() {
+    gr.mu.RLock()
+    guild, ok := gr.guilds[id]
+    gr.mu.RUnlock()
+
+    return func() {
+        gr.mu.Lock()
+        defer gr.mu.Unlock()
+
+        if ok {
+            gr.guilds[id] = guild
+        } else {
+            delete(gr.guilds, id)
+        }
+    }
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/model"
+    "sort"
+    "sync"
+)
+
+type LobbyRepository struct {
+    mu      sync.RWMutex
+    lobbies map[string]model.Lobby
+}
+
+func NewLobby() *LobbyRepository {
+    return &LobbyRepository{lobbies: make(map[string]model.Lobby)}
+}
+
+func (lr *LobbyRepository) GetLobby(id string, guildId string) (model.Lobby, error) {
+    lr.mu.RLock()
+    defer lr.mu.RUnlock()
+
+    lobby, ok := lr.lobbies[id]
+    if !ok || lobby.GuildID != guildId {
+        return model.Lobby{}, fmt.Errorf("memory: unable to get lobby[%s] for guild[%s]: %w", id, guildId, sql.ErrNoRows)
+    }
+
+    return lobby, nil
+}
+
+func (lr *LobbyRepository) GetLobbies(guildId string) ([]model.Lobby, error) {
+    lr.mu.RLock()
+    defer lr.mu.RUnlock()
+
+    var lobbies []model.Lobby
+    for _, lobby := range lr.lobbies {
+        if lobby.GuildID == guildId {
+            lobbies = append(lobbies, lobby)
+        }
+    }
+
+    sort.Slice(lobbies, func(a, b int) bool {
+        return lobbies[a].Id < lobbies[b].Id
+    })
+
+    return lobbies, nil
+}
+
+// SetLobby registers the lobby with its name template and capacity, registered lobbies are kept as they are.
+func (lr *LobbyRepository) SetLobby(lobby *model.Lobby) (int64, error) {
+    lr.mu.Lock()
+    defer lr.mu.Unlock()
+
+    if _, ok := lr.lobbies[lobby.Id]; ok {
+        return 0, nil
+    }
+
+    lr.lobbies[lobby.Id] = model.Lobby{
+        Id:         lobby.Id,
+        CategoryID: lobby.CategoryID,
+        GuildID:    lobby.GuildID,
+        Template:   lobby.Template,
+        Capacity:   lobby.Capacity,
+    }
+
+    return 1, nil
+}
+
+// UpsertLobby changes the settings that are set in lobby and keeps the rest.
+func (lr *LobbyRepository) UpsertLobby(lobby *model.Lobby) error {
+    lr.mu.Lock()
+    defer lr.mu.Unlock()
+
+    stored, ok := lr.lobbies[lobby.Id]
+    if !ok {
+        stored = model.Lobby{Id: lobby.Id}
+    }
+
+    mergeString(&stored.Template, lobby.Template)
+    mergeInt32(&stored.Capacity, lobby.Capacity)
+    mergeInt32(&stored.Naming, lobby.Naming)
+    mergeInt32(&stored.Grace, lobby.Grace)
+    mergeInt32(&stored.Bitrate, lobby.Bitrate)
+    mergeString(&stored.RTCRegion, lobby.RTCRegion)
+    mergeInt32(&stored.VideoQuality, lobby.VideoQuality)
+    mergeBool(&stored.NSFW, lobby.NSFW)
+    mergeInt32(&stored.TextChannel, lobby.TextChannel)
+    mergeBool(&stored.Activity, lobby.Activity)
+    mergeBool(&stored.Overrides, lobby.Overrides)
+    mergeInt32(&stored.Cooldown, lobby.Cooldown)
+    mergeInt32(&stored.BurstLimit, lobby.BurstLimit)
+    mergeBool(&stored.BlockSpam, lobby.BlockSpam)
+    mergeInt32(&stored.MaxRooms, lobby.MaxRooms)
+    mergeString(&stored.WaitingID, lobby.WaitingID)
+    mergeInt32(&stored.Permissions, lobby.Permissions)
+    mergeInt32(&stored.Placement, lobby.Placement)
+    mergeBool(&stored.AutoOverflow, lobby.AutoOverflow)
+
+    lr.lobbies[lobby.Id] = stored
+
+    return nil
+}
+
+func (lr *LobbyRepository) DeleteLobby(id string, guildId string) (int64, error) {
+    lr.mu.Lock()
+    defer lr.mu.Unlock()
+
+    lobby, ok := lr.lobbies[id]
+    if !ok || lobby.GuildID != guildId {
+        return 0, nil
+    }
+
+    delete(lr.lobbies, id)
+
+    return 1, nil
+}
+
+func mergeString(stored *sql.NullString, value sql.NullString) {
+    if value.Valid {
+        *stored = value
+    }
+}
+
+func mergeInt32(stored *sql.NullInt32, value sql.NullInt32) {
+    if value.Valid {
+        *stored = value
+    }
+}
+
+func mergeBool(stored *sql.NullBool, value sql.NullBool) {
+    if value.Valid {
+        *stored = value
+    }
+}
+
+// backup returns a function restoring the lobby as it is now.
+func (lr *LobbyRepository) backup(id string) func() {
+    lr.mu.RLock()
+    lobby, ok := lr.lobbies[id]
+    lr.mu.RUnlock()
+
+    return func() {
+        lr.mu.Lock()
+        defer lr.mu.Unlock()
+
+        if ok {
+            lr.lobbies[id] = lobby
+        } else {
+            delete(lr.lobbies, id)
+        }
+    }
+}
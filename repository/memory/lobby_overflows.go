@@ -0,0 +1,89 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "sync"
+)
+
+type LobbyOverflowsRepository struct {
+    mu        sync.RWMutex
+    overflows map[string][]model.LobbyOverflow // Overflows by lobby, ordered by position
+}
+
+func NewLobbyOverflows() *LobbyOverflowsRepository {
+    return &LobbyOverflowsRepository{overflows: make(map[string][]model.LobbyOverflow)}
+}
+
+func (lor *LobbyOverflowsRepository) GetLobbyOverflows(lobbyId string) ([]model.LobbyOverflow, error) {
+    lor.mu.RLock()
+    defer lor.mu.RUnlock()
+
+    return append([]model.LobbyOverflow(nil), lor.overflows[lobbyId]...), nil
+}
+
+// AddLobbyOverflow appends the category to the end of the lobby overflow list.
+func (lor *LobbyOverflowsRepository) AddLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    overflows := lor.overflows[lobbyId]
+    position := 1
+    for _, overflow := range overflows {
+        if overflow.CategoryID == categoryId {
+            return 0, nil
+        }
+
+        position = max(position, overflow.Position+1)
+    }
+
+    lor.overflows[lobbyId] = append(overflows, model.LobbyOverflow{
+        LobbyID:    lobbyId,
+        CategoryID: categoryId,
+        Position:   position,
+    })
+
+    return 1, nil
+}
+
+func (lor *LobbyOverflowsRepository) DeleteLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    overflows := lor.overflows[lobbyId]
+    for index, overflow := range overflows {
+        if overflow.CategoryID == categoryId {
+            lor.overflows[lobbyId] = append(overflows[:index:index], overflows[index+1:]...)
+            return 1, nil
+        }
+    }
+
+    return 0, nil
+}
+
+func (lor *LobbyOverflowsRepository) DeleteLobbyOverflows(lobbyId string) error {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    delete(lor.overflows, lobbyId)
+
+    return nil
+}
+
+// backup returns a function restoring the overflow categories of the lobby as they are now.
+func (lor *LobbyOverflowsRepository) backup(lobbyId string) func() {
+    lor.mu.RLock()
+    overflows, ok := lor.overflows[lobbyId]
+    overflows = append([]model.LobbyOverflow(nil), overflows...)
+    lor.mu.RUnlock()
+
+    return func() {
+        lor.mu.Lock()
+        defer lor.mu.Unlock()
+
+        if ok {
+            lor.overflows[lobbyId] = overflows
+        } else {
+            delete(lor.overflows, lobbyId)
+        }
+    }
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "sync"
+)
+
+type LobbyOverwritesRepository struct {
+    mu         sync.RWMutex
+    overwrites map[string][]model.LobbyOverwrite // Overwrites by lobby
+}
+
+func NewLobbyOverwrites() *LobbyOverwritesRepository {
+    return &LobbyOverwritesRepository{overwrites: make(map[string][]model.LobbyOverwrite)}
+}
+
+func (lor *LobbyOverwritesRepository) GetLobbyOverwrites(lobbyId string) ([]model.LobbyOverwrite, error) {
+    lor.mu.RLock()
+    defer lor.mu.RUnlock()
+
+    return append([]model.LobbyOverwrite(nil), lor.overwrites[lobbyId]...), nil
+}
+
+func (lor *LobbyOverwritesRepository) SetLobbyOverwrite(overwrite *model.LobbyOverwrite) error {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    overwrites := lor.overwrites[overwrite.LobbyID]
+    for index := range overwrites {
+        if overwrites[index].TargetID == overwrite.TargetID {
+            overwrites[index] = *overwrite
+            return nil
+        }
+    }
+
+    lor.overwrites[overwrite.LobbyID] = append(overwrites, *overwrite)
+
+    return nil
+}
+
+func (lor *LobbyOverwritesRepository) DeleteLobbyOverwrite(lobbyId string, targetId string) error {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    overwrites := lor.overwrites[lobbyId]
+    for index, overwrite := range overwrites {
+        if overwrite.TargetID == targetId {
+            lor.overwrites[lobbyId] = append(overwrites[:index:index], overwrites[index+1:]...)
+            break
+        }
+    }
+
+    return nil
+}
+
+func (lor *LobbyOverwritesRepository) DeleteLobbyOverwrites(lobbyId string) error {
+    lor.mu.Lock()
+    defer lor.mu.Unlock()
+
+    delete(lor.overwrites, lobbyId)
+
+    return nil
+}
+
+// backup returns a function restoring the overwrites of the lobby as they are now.
+func (lor *LobbyOverwritesRepository) backup(lobbyId string) func() {
+    lor.mu.RLock()
+    overwrites, ok := lor.overwrites[lobbyId]
+    overwrites = append([]model.LobbyOverwrite(nil), overwrites...)
+    lor.mu.RUnlock()
+
+    return func() {
+        lor.mu.Lock()
+        defer lor.mu.Unlock()
+
+        if ok {
+            lor.overwrites[lobbyId] = overwrites
+        } else {
+            delete(lor.overwrites, lobbyId)
+        }
+    }
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "sync"
+)
+
+type LobbyRolesRepository struct {
+    mu    sync.RWMutex
+    roles map[string][]model.LobbyRole // Roles by lobby
+}
+
+func NewLobbyRoles() *LobbyRolesRepository {
+    return &LobbyRolesRepository{roles: make(map[string][]model.LobbyRole)}
+}
+
+func (lrr *LobbyRolesRepository) GetLobbyRoles(lobbyId string) ([]model.LobbyRole, error) {
+    lrr.mu.RLock()
+    defer lrr.mu.RUnlock()
+
+    return append([]model.LobbyRole(nil), lrr.roles[lobbyId]...), nil
+}
+
+func (lrr *LobbyRolesRepository) SetLobbyRole(role *model.LobbyRole) error {
+    lrr.mu.Lock()
+    defer lrr.mu.Unlock()
+
+    roles := lrr.roles[role.LobbyID]
+    for index := range roles {
+        if roles[index].RoleID == role.RoleID {
+            roles[index].Allowed = role.Allowed
+            return nil
+        }
+    }
+
+    lrr.roles[role.LobbyID] = append(roles, *role)
+
+    return nil
+}
+
+func (lrr *LobbyRolesRepository) DeleteLobbyRole(lobbyId string, roleId string) (int64, error) {
+    lrr.mu.Lock()
+    defer lrr.mu.Unlock()
+
+    roles := lrr.roles[lobbyId]
+    for index, role := range roles {
+        if role.RoleID == roleId {
+            lrr.roles[lobbyId] = append(roles[:index:index], roles[index+1:]...)
+            return 1, nil
+        }
+    }
+
+    return 0, nil
+}
+
+func (lrr *LobbyRolesRepository) DeleteLobbyRoles(lobbyId string) error {
+    lrr.mu.Lock()
+    defer lrr.mu.Unlock()
+
+    delete(lrr.roles, lobbyId)
+
+    return nil
+}
+
+// backup returns a function restoring the roles of the lobby as they are now.
+func (lrr *LobbyRolesRepository) backup(lobbyId string) func() {
+    lrr.mu.RLock()
+    roles, ok := lrr.roles[lobbyId]
+    roles = append([]model.LobbyRole(nil), roles...)
+    lrr.mu.RUnlock()
+
+    return func() {
+        lrr.mu.Lock()
+        defer lrr.mu.Unlock()
+
+        if ok {
+            lrr.roles[lobbyId] = roles
+        } else {
+            delete(lrr.roles, lobbyId)
+        }
+    }
+}
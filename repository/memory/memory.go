@@ -0,0 +1,16 @@
+// Package memory keeps the bot data in maps for tests and ephemeral runs, nothing survives a restart.
+package memory
+
+import "hometown-bot/repository"
+
+var _ repository.ChannelRepository = (*ChannelRepository)(nil)
+var _ repository.ChannelMembersRepository = (*ChannelMembersRepository)(nil)
+var _ repository.ChannelPermitsRepository = (*ChannelPermitsRepository)(nil)
+var _ repository.GuildRepository = (*GuildRepository)(nil)
+var _ repository.LobbyRepository = (*LobbyRepository)(nil)
+var _ repository.LobbyOverflowsRepository = (*LobbyOverflowsRepository)(nil)
+var _ repository.LobbyOverwritesRepository = (*LobbyOverwritesRepository)(nil)
+var _ repository.LobbyRolesRepository = (*LobbyRolesRepository)(nil)
+var _ repository.RoomBansRepository = (*RoomBansRepository)(nil)
+var _ repository.UserPreferencesRepository = (*UserPreferencesRepository)(nil)
+var _ repository.Transactor = (*Transactor)(nil)
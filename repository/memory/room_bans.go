@@ -0,0 +1,58 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "sync"
+)
+
+type RoomBansRepository struct {
+    mu   sync.RWMutex
+    bans []model.RoomBan
+}
+
+func NewRoomBans() *RoomBansRepository {
+    return &RoomBansRepository{}
+}
+
+func (rbr *RoomBansRepository) GetRoomBans(ownerId string, guildId string) ([]model.RoomBan, error) {
+    rbr.mu.RLock()
+    defer rbr.mu.RUnlock()
+
+    var bans []model.RoomBan
+    for _, ban := range rbr.bans {
+        if ban.OwnerID == ownerId && ban.GuildID == guildId {
+            bans = append(bans, ban)
+        }
+    }
+
+    return bans, nil
+}
+
+func (rbr *RoomBansRepository) SetRoomBan(ban *model.RoomBan) error {
+    rbr.mu.Lock()
+    defer rbr.mu.Unlock()
+
+    for _, stored := range rbr.bans {
+        if stored == *ban {
+            return nil
+        }
+    }
+
+    rbr.bans = append(rbr.bans, *ban)
+
+    return nil
+}
+
+func (rbr *RoomBansRepository) DeleteRoomBan(ban *model.RoomBan) (int64, error) {
+    rbr.mu.Lock()
+    defer rbr.mu.Unlock()
+
+    for index, stored := range rbr.bans {
+        if stored == *ban {
+            rbr.bans = append(rbr.bans[:index:index], rbr.bans[index+1:]...)
+            return 1, nil
+        }
+    }
+
+    return 0, nil
+}
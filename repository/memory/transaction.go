@@ -0,0 +1,264 @@
+package memory
+
+import (
+    "hometown-bot/model"
+    "hometown-bot/repository"
+    "sync"
+)
+
+// Transactor rolls back a failed unit of work by restoring everything it has written.
+// Units of work run one at a time.
+type Transactor struct {
+    mu              sync.Mutex
+    channels        *ChannelRepository
+    channelMembers  *ChannelMembersRepository
+    channelPermits  *ChannelPermitsRepository
+    guilds          *GuildRepository
+    lobbies         *LobbyRepository
+    lobbyRoles      *LobbyRolesRepository
+    lobbyOverwrites *LobbyOverwritesRepository
+    lobbyOverflows  *LobbyOverflowsRepository
+}
+
+func NewTransactor(
+    channels *ChannelRepository,
+    channelMembers *ChannelMembersRepository,
+    channelPermits *ChannelPermitsRepository,
+    guilds *GuildRepository,
+    lobbies *LobbyRepository,
+    lobbyRoles *LobbyRolesRepository,
+    lobbyOverwrites *LobbyOverwritesRepository,
+    lobbyOverflows *LobbyOverflowsRepository,
+) *Transactor {
+    return &Transactor{
+        channels:        channels,
+        channelMembers:  channelMembers,
+        channelPermits:  channelPermits,
+        guilds:          guilds,
+        lobbies:         lobbies,
+        lobbyRoles:      lobbyRoles,
+        lobbyOverwrites: lobbyOverwrites,
+        lobbyOverflows:  lobbyOverflows,
+    }
+}
+
+func (t *Transactor) InTx(fn func(tx repository.Tx) error) error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    tx := &transaction{transactor: t}
+    if err := fn(tx); err != nil {
+        for index := len(tx.undo) - 1; index >= 0; index-- {
+            tx.undo[index]()
+        }
+
+        return err
+    }
+
+    return nil
+}
+
+type transaction struct {
+    transactor *Transactor
+    undo       []func() // Restores the state before each write, run in reverse order
+}
+
+func (tx *transaction) Channels() repository.ChannelRepository {
+    return txChannels{ChannelRepository: tx.transactor.channels, tx: tx}
+}
+
+func (tx *transaction) ChannelMembers() repository.ChannelMembersRepository {
+    return txChannelMembers{ChannelMembersRepository: tx.transactor.channelMembers, tx: tx}
+}
+
+func (tx *transaction) ChannelPermits() repository.ChannelPermitsRepository {
+    return txChannelPermits{ChannelPermitsRepository: tx.transactor.channelPermits, tx: tx}
+}
+
+func (tx *transaction) Guilds() repository.GuildRepository {
+    return txGuilds{GuildRepository: tx.transactor.guilds, tx: tx}
+}
+
+func (tx *transaction) Lobbies() repository.LobbyRepository {
+    return txLobbies{LobbyRepository: tx.transactor.lobbies, tx: tx}
+}
+
+func (tx *transaction) LobbyRoles() repository.LobbyRolesRepository {
+    return txLobbyRoles{LobbyRolesRepository: tx.transactor.lobbyRoles, tx: tx}
+}
+
+func (tx *transaction) LobbyOverwrites() repository.LobbyOverwritesRepository {
+    return txLobbyOverwrites{LobbyOverwritesRepository: tx.transactor.lobbyOverwrites, tx: tx}
+}
+
+func (tx *transaction) LobbyOverflows() repository.LobbyOverflowsRepository {
+    return txLobbyOverflows{LobbyOverflowsRepository: tx.transactor.lobbyOverflows, tx: tx}
+}
+
+type txChannels struct {
+    *ChannelRepository
+    tx  *transaction
+}
+
+func (c txChannels) SetChannel(channel *model.Channel) error {
+    c.tx.undo = append(c.tx.undo, c.backup(channel.Id))
+    return c.ChannelRepository.SetChannel(channel)
+}
+
+func (c txChannels) SetChannelOwner(id string, ownerId string) error {
+    c.tx.undo = append(c.tx.undo, c.backup(id))
+    return c.ChannelRepository.SetChannelOwner(id, ownerId)
+}
+
+func (c txChannels) SetChannelPrivacy(id string, privacy model.Privacy) error {
+    c.tx.undo = append(c.tx.undo, c.backup(id))
+    return c.ChannelRepository.SetChannelPrivacy(id, privacy)
+}
+
+func (c txChannels) SetChannelDeleteAt(id string, deleteAt int64) error {
+    c.tx.undo = append(c.tx.undo, c.backup(id))
+    return c.ChannelRepository.SetChannelDeleteAt(id, deleteAt)
+}
+
+func (c txChannels) DeleteChannel(id string) error {
+    c.tx.undo = append(c.tx.undo, c.backup(id))
+    return c.ChannelRepository.DeleteChannel(id)
+}
+
+type txChannelMembers struct {
+    *ChannelMembersRepository
+    tx  *transaction
+}
+
+func (m txChannelMembers) SetChannelMember(guildId string, userId string, channelId string) error {
+    m.tx.undo = append(m.tx.undo, m.backup(func(key memberKey, _ channelMember) bool {
+        return key == memberKey{guildId: guildId, userId: userId}
+    }))
+    return m.ChannelMembersRepository.SetChannelMember(guildId, userId, channelId)
+}
+
+func (m txChannelMembers) DeleteChannelMember(guildId string, userId string, channelId string) error {
+    m.tx.undo = append(m.tx.undo, m.backup(func(key memberKey, _ channelMember) bool {
+        return key == memberKey{guildId: guildId, userId: userId}
+    }))
+    return m.ChannelMembersRepository.DeleteChannelMember(guildId, userId, channelId)
+}
+
+func (m txChannelMembers) DeleteChannelMembers(guildId string, channelId string) error {
+    m.tx.undo = append(m.tx.undo, m.backup(func(key memberKey, member channelMember) bool {
+        return key.guildId == guildId && member.channelId == channelId
+    }))
+    return m.ChannelMembersRepository.DeleteChannelMembers(guildId, channelId)
+}
+
+func (m txChannelMembers) DeleteGuildChannelMembers(guildId string) error {
+    m.tx.undo = append(m.tx.undo, m.backup(func(key memberKey, _ channelMember) bool {
+        return key.guildId == guildId
+    }))
+    return m.ChannelMembersRepository.DeleteGuildChannelMembers(guildId)
+}
+
+type txChannelPermits struct {
+    *ChannelPermitsRepository
+    tx  *transaction
+}
+
+func (p txChannelPermits) SetChannelPermit(permit *model.ChannelPermit) error {
+    p.tx.undo = append(p.tx.undo, p.backup(permit.ChannelID))
+    return p.ChannelPermitsRepository.SetChannelPermit(permit)
+}
+
+func (p txChannelPermits) DeleteChannelPermits(channelId string) error {
+    p.tx.undo = append(p.tx.undo, p.backup(channelId))
+    return p.ChannelPermitsRepository.DeleteChannelPermits(channelId)
+}
+
+type txGuilds struct {
+    *GuildRepository
+    tx  *transaction
+}
+
+func (g txGuilds) UpsertGuild(guild *model.Guild) error {
+    g.tx.undo = append(g.tx.undo, g.backup(guild.Id))
+    return g.GuildRepository.UpsertGuild(guild)
+}
+
+type txLobbies struct {
+    *LobbyRepository
+    tx  *transaction
+}
+
+func (l txLobbies) SetLobby(lobby *model.Lobby) (int64, error) {
+    l.tx.undo = append(l.tx.undo, l.backup(lobby.Id))
+    return l.LobbyRepository.SetLobby(lobby)
+}
+
+func (l txLobbies) UpsertLobby(lobby *model.Lobby) error {
+    l.tx.undo = append(l.tx.undo, l.backup(lobby.Id))
+    return l.LobbyRepository.UpsertLobby(lobby)
+}
+
+func (l txLobbies) DeleteLobby(id string, guildId string) (int64, error) {
+    l.tx.undo = append(l.tx.undo, l.backup(id))
+    return l.LobbyRepository.DeleteLobby(id, guildId)
+}
+
+type txLobbyRoles struct {
+    *LobbyRolesRepository
+    tx  *transaction
+}
+
+func (r txLobbyRoles) SetLobbyRole(role *model.LobbyRole) error {
+    r.tx.undo = append(r.tx.undo, r.backup(role.LobbyID))
+    return r.LobbyRolesRepository.SetLobbyRole(role)
+}
+
+func (r txLobbyRoles) DeleteLobbyRole(lobbyId string, roleId string) (int64, error) {
+    r.tx.undo = append(r.tx.undo, r.backup(lobbyId))
+    return r.LobbyRolesRepository.DeleteLobbyRole(lobbyId, roleId)
+}
+
+func (r txLobbyRoles) DeleteLobbyRoles(lobbyId string) error {
+    r.tx.undo = append(r.tx.undo, r.backup(lobbyId))
+    return r.LobbyRolesRepository.DeleteLobbyRoles(lobbyId)
+}
+
+type txLobbyOverwrites struct {
+    *LobbyOverwritesRepository
+    tx  *transaction
+}
+
+func (o txLobbyOverwrites) SetLobbyOverwrite(overwrite *model.LobbyOverwrite) error {
+    o.tx.undo = append(o.tx.undo, o.backup(overwrite.LobbyID))
+    return o.LobbyOverwritesRepository.SetLobbyOverwrite(overwrite)
+}
+
+func (o txLobbyOverwrites) DeleteLobbyOverwrite(lobbyId string, targetId string) error {
+    o.tx.undo = append(o.tx.undo, o.backup(lobbyId))
+    return o.LobbyOverwritesRepository.DeleteLobbyOverwrite(lobbyId, targetId)
+}
+
+func (o txLobbyOverwrites) DeleteLobbyOverwrites(lobbyId string) error {
+    o.tx.undo = append(o.tx.undo, o.backup(lobbyId))
+    return o.LobbyOverwritesRepository.DeleteLobbyOverwrites(lobbyId)
+}
+
+type txLobbyOverflows struct {
+    *LobbyOverflowsRepository
+    tx  *transaction
+}
+
+func (o txLobbyOverflows) AddLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    o.tx.undo = append(o.tx.undo, o.backup(lobbyId))
+    return o.LobbyOverflowsRepository.AddLobbyOverflow(lobbyId, categoryId)
+}
+
+func (o txLobbyOverflows) DeleteLobbyOverflow(lobbyId string, categoryId string) (int64, error) {
+    o.tx.undo = append(o.tx.undo, o.backup(lobbyId))
+    return o.LobbyOverflowsRepository.DeleteLobbyOverflow(lobbyId, categoryId)
+}
+
+func (o txLobbyOverflows) DeleteLobbyOverflows(lobbyId string) error {
+    o.tx.undo = append(o.tx.undo, o.backup(lobbyId))
+    return o.LobbyOverflowsRepository.DeleteLobbyOverflows(lobbyId)
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/model"
+    "sync"
+)
+
+type preferenceKey struct {
+    userId  string
+    guildId string
+}
+
+type UserPreferencesRepository struct {
+    mu          sync.RWMutex
+    preferences map[preferenceKey]model.UserPreference
+}
+
+func NewUserPreferences() *UserPreferencesRepository {
+    return &UserPreferencesRepository{preferences: make(map[preferenceKey]model.UserPreference)}
+}
+
+func (upr *UserPreferencesRepository) GetUserPreference(userId string, guildId string) (model.UserPreference, error) {
+    upr.mu.RLock()
+    defer upr.mu.RUnlock()
+
+    preference, ok := upr.preferences[preferenceKey{userId: userId, guildId: guildId}]
+    if !ok {
+        return model.UserPreference{}, fmt.Errorf(
+            "memory: unable to get user[%s] preference for guild[%s]: %w",
+            userId,
+            guildId,
+            sql.ErrNoRows,
+        )
+    }
+
+    preference.Permits = append([]string(nil), preference.Permits...)
+
+    return preference, nil
+}
+
+func (upr *UserPreferencesRepository) SetUserPreference(preference *model.UserPreference) error {
+    upr.mu.Lock()
+    defer upr.mu.Unlock()
+
+    stored := *preference
+    stored.Permits = append([]string(nil), preference.Permits...)
+    upr.preferences[preferenceKey{userId: preference.UserID, guildId: preference.GuildID}] = stored
+
+    return nil
+}
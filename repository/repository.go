@@ -0,0 +1,88 @@
+package repository
+
+import "hometown-bot/model"
+
+// Missing rows are reported as wrapped sql.ErrNoRows by every implementation.
+
+type ChannelRepository interface {
+    GetChannel(id string, guildId string) (model.Channel, error)
+    GetChannels(guildId string) ([]model.Channel, error)
+    GetChannelIndexes(parentId string) ([]int, error)
+    SetChannel(channel *model.Channel) error
+    SetChannelOwner(id string, ownerId string) error
+    SetChannelPrivacy(id string, privacy model.Privacy) error
+    SetChannelDeleteAt(id string, deleteAt int64) error
+    DeleteChannel(id string) error
+}
+
+type ChannelMembersRepository interface {
+    GetChannelMembersCount(guildId string, channelId string) (int, error)
+    GetChannelMembers(guildId string, channelId string) ([]string, error)
+    SetChannelMember(guildId string, userId string, channelId string) error
+    DeleteChannelMember(guildId string, userId string, channelId string) error
+    DeleteChannelMembers(guildId string, channelId string) error
+    DeleteGuildChannelMembers(guildId string) error
+}
+
+type ChannelPermitsRepository interface {
+    GetChannelPermits(channelId string) ([]model.ChannelPermit, error)
+    SetChannelPermit(permit *model.ChannelPermit) error
+    DeleteChannelPermits(channelId string) error
+}
+
+type GuildRepository interface {
+    GetGuild(id string) (model.Guild, error)
+    UpsertGuild(guild *model.Guild) error
+}
+
+type LobbyRepository interface {
+    GetLobby(id string, guildId string) (model.Lobby, error)
+    GetLobbies(guildId string) ([]model.Lobby, error)
+    SetLobby(lobby *model.Lobby) (int64, error)
+    UpsertLobby(lobby *model.Lobby) error
+    DeleteLobby(id string, guildId string) (int64, error)
+}
+
+type LobbyOverflowsRepository interface {
+    GetLobbyOverflows(lobbyId string) ([]model.LobbyOverflow, error)
+    AddLobbyOverflow(lobbyId string, categoryId string) (int64, error)
+    DeleteLobbyOverflow(lobbyId string, categoryId string) (int64, error)
+    DeleteLobbyOverflows(lobbyId string) error
+}
+
+type LobbyOverwritesRepository interface {
+    GetLobbyOverwrites(lobbyId string) ([]model.LobbyOverwrite, error)
+    SetLobbyOverwrite(overwrite *model.LobbyOverwrite) error
+    DeleteLobbyOverwrite(lobbyId string, targetId string) error
+    DeleteLobbyOverwrites(lobbyId string) error
+}
+
+type LobbyRolesRepository interface {
+    GetLobbyRoles(lobbyId string) ([]model.LobbyRole, error)
+    SetLobbyRole(role *model.LobbyRole) error
+    DeleteLobbyRole(lobbyId string, roleId string) (int64, error)
+    DeleteLobbyRoles(lobbyId string) error
+}
+
+type RoomBansRepository interface {
+    GetRoomBans(ownerId string, guildId string) ([]model.RoomBan, error)
+    SetRoomBan(ban *model.RoomBan) error
+    DeleteRoomBan(ban *model.RoomBan) (int64, error)
+}
+
+type UserPreferencesRepository interface {
+    GetUserPreference(userId string, guildId string) (model.UserPreference, error)
+    SetUserPreference(preference *model.UserPreference) error
+}
+
+var _ ChannelRepository = (*SQLChannelRepository)(nil)
+var _ ChannelMembersRepository = (*SQLChannelMembersRepository)(nil)
+var _ ChannelPermitsRepository = (*SQLChannelPermitsRepository)(nil)
+var _ GuildRepository = (*SQLGuildRepository)(nil)
+var _ LobbyRepository = (*SQLLobbyRepository)(nil)
+var _ LobbyOverflowsRepository = (*SQLLobbyOverflowsRepository)(nil)
+var _ LobbyOverwritesRepository = (*SQLLobbyOverwritesRepository)(nil)
+var _ LobbyRolesRepository = (*SQLLobbyRolesRepository)(nil)
+var _ RoomBansRepository = (*SQLRoomBansRepository)(nil)
+var _ UserPreferencesRepository = (*SQLUserPreferencesRepository)(nil)
+var _ Transactor = (*SQLTransactor)(nil)
@@ -0,0 +1,84 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+)
+
+type SQLRoomBansRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewRoomBans(db *sql.DB, dialect storage.Dialect) *SQLRoomBansRepository {
+    return &SQLRoomBansRepository{db: db, dialect: dialect}
+}
+
+const SelectRoomBans = `
+SELECT owner_id, guild_id, user_id
+FROM room_bans
+WHERE (owner_id = ? AND guild_id = ?)
+`
+
+func (rbr *SQLRoomBansRepository) GetRoomBans(ownerId string, guildId string) ([]model.RoomBan, error) {
+    log.Debug().Printf("repo: get owner[%s] bans for guild[%s]", ownerId, guildId)
+
+    rows, err := rbr.db.Query(rbr.dialect.Query(SelectRoomBans), ownerId, guildId)
+    if err != nil {
+        return nil, fmt.Errorf("repo: unable to get owner[%s] bans for guild[%s]: %w", ownerId, guildId, err)
+    }
+    defer rows.Close()
+
+    var bans []model.RoomBan
+    for rows.Next() {
+        var ban model.RoomBan
+
+        if err := rows.Scan(&ban.OwnerID, &ban.GuildID, &ban.UserID); err != nil {
+            return nil, fmt.Errorf("repo: unable to get owner[%s] bans for guild[%s]: %w", ownerId, guildId, err)
+        }
+
+        bans = append(bans, ban)
+    }
+
+    return bans, nil
+}
+
+const InsertRoomBan = `
+INSERT INTO room_bans (owner_id, guild_id, user_id)
+VALUES(?, ?, ?)
+ON CONFLICT DO NOTHING
+`
+
+func (rbr *SQLRoomBansRepository) SetRoomBan(ban *model.RoomBan) error {
+    log.Debug().Printf("repo: set owner[%s] ban for user[%s]", ban.OwnerID, ban.UserID)
+
+    if _, err := rbr.db.Exec(rbr.dialect.Query(InsertRoomBan), ban.OwnerID, ban.GuildID, ban.UserID); err != nil {
+        return fmt.Errorf("repo: unable to set owner[%s] ban for user[%s]: %w", ban.OwnerID, ban.UserID, err)
+    }
+
+    return nil
+}
+
+const DeleteRoomBan = `
+DELETE FROM room_bans
+WHERE (owner_id = ? AND guild_id = ? AND user_id = ?)
+`
+
+func (rbr *SQLRoomBansRepository) DeleteRoomBan(ban *model.RoomBan) (int64, error) {
+    log.Debug().Printf("repo: delete owner[%s] ban for user[%s]", ban.OwnerID, ban.UserID)
+
+    result, err := rbr.db.Exec(rbr.dialect.Query(DeleteRoomBan), ban.OwnerID, ban.GuildID, ban.UserID)
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete owner[%s] ban for user[%s]: %w", ban.OwnerID, ban.UserID, err)
+    }
+
+    affectedRows, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("repo: unable to delete owner[%s] ban for user[%s]: %w", ban.OwnerID, ban.UserID, err)
+    }
+
+    return affectedRows, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/storage"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx, so SQL repositories work inside and outside transactions.
+type querier interface {
+    Exec(query string, args ...any) (sql.Result, error)
+    Query(query string, args ...any) (*sql.Rows, error)
+    QueryRow(query string, args ...any) *sql.Row
+}
+
+// Tx gives access to repositories whose writes belong to a single unit of work.
+type Tx interface {
+    Channels() ChannelRepository
+    ChannelMembers() ChannelMembersRepository
+    ChannelPermits() ChannelPermitsRepository
+    Guilds() GuildRepository
+    Lobbies() LobbyRepository
+    LobbyRoles() LobbyRolesRepository
+    LobbyOverwrites() LobbyOverwritesRepository
+    LobbyOverflows() LobbyOverflowsRepository
+}
+
+// Transactor runs units of work, their writes are kept together or not at all.
+type Transactor interface {
+    // InTx commits the writes of fn if it returns nil, otherwise they are rolled back and the error is returned.
+    InTx(fn func(tx Tx) error) error
+}
+
+type SQLTransactor struct {
+    db      *sql.DB
+    dialect storage.Dialect
+}
+
+func NewTransactor(db *sql.DB, dialect storage.Dialect) *SQLTransactor {
+    return &SQLTransactor{db: db, dialect: dialect}
+}
+
+func (t *SQLTransactor) InTx(fn func(tx Tx) error) error {
+    log.Debug().Println("repo: begin transaction")
+
+    tx, err := t.db.Begin()
+    if err != nil {
+        return fmt.Errorf("repo: unable to begin transaction: %w", err)
+    }
+
+    if err := fn(&sqlTx{tx: tx, dialect: t.dialect}); err != nil {
+        log.Debug().Println("repo: roll back transaction")
+        if rollbackErr := tx.Rollback(); rollbackErr != nil {
+            log.Error().Printf("repo: unable to roll back transaction: %v", rollbackErr)
+        }
+
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("repo: unable to commit transaction: %w", err)
+    }
+
+    return nil
+}
+
+type sqlTx struct {
+    tx      *sql.Tx
+    dialect storage.Dialect
+}
+
+func (t *sqlTx) Channels() ChannelRepository {
+    return &SQLChannelRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) ChannelMembers() ChannelMembersRepository {
+    return &SQLChannelMembersRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) ChannelPermits() ChannelPermitsRepository {
+    return &SQLChannelPermitsRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) Guilds() GuildRepository {
+    return &SQLGuildRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) Lobbies() LobbyRepository {
+    return &SQLLobbyRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) LobbyRoles() LobbyRolesRepository {
+    return &SQLLobbyRolesRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) LobbyOverwrites() LobbyOverwritesRepository {
+    return &SQLLobbyOverwritesRepository{db: t.tx, dialect: t.dialect}
+}
+
+func (t *sqlTx) LobbyOverflows() LobbyOverflowsRepository {
+    return &SQLLobbyOverflowsRepository{db: t.tx, dialect: t.dialect}
+}
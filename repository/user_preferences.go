@@ -0,0 +1,92 @@
+package repository
+
+import (
+    "database/sql"
+    "fmt"
+    "hometown-bot/log"
+    "hometown-bot/model"
+    "hometown-bot/storage"
+    "strings"
+)
+
+type SQLUserPreferencesRepository struct {
+    db      querier
+    dialect storage.Dialect
+}
+
+func NewUserPreferences(db *sql.DB, dialect storage.Dialect) *SQLUserPreferencesRepository {
+    return &SQLUserPreferencesRepository{db: db, dialect: dialect}
+}
+
+const SelectUserPreference = `
+SELECT user_id, guild_id, name, coalesce(user_limit, 0), coalesce(privacy, 0), coalesce(permits, '')
+FROM user_preferences
+WHERE (user_id = ? AND guild_id = ?)
+`
+
+func (upr *SQLUserPreferencesRepository) GetUserPreference(userId string, guildId string) (model.UserPreference, error) {
+    log.Debug().Printf("repo: get user[%s] preference for guild[%s]", userId, guildId)
+
+    var preference model.UserPreference
+    var permits string
+    if err := upr.db.QueryRow(
+        upr.dialect.Query(SelectUserPreference),
+        userId,
+        guildId,
+    ).Scan(
+        &preference.UserID,
+        &preference.GuildID,
+        &preference.Name,
+        &preference.UserLimit,
+        &preference.Privacy,
+        &permits,
+    ); err != nil {
+        return model.UserPreference{}, fmt.Errorf(
+            "repo: unable to get user[%s] preference for guild[%s]: %w",
+            userId,
+            guildId,
+            err,
+        )
+    }
+
+    if permits != "" {
+        preference.Permits = strings.Split(permits, ",")
+    }
+
+    return preference, nil
+}
+
+const UpsertUserPreference = `
+INSERT INTO user_preferences (user_id, guild_id, name, user_limit, privacy, permits)
+VALUES(?, ?, ?, ?, ?, ?)
+ON CONFLICT(user_id, guild_id)
+DO UPDATE
+SET
+	name = EXCLUDED.name,
+	user_limit = EXCLUDED.user_limit,
+	privacy = EXCLUDED.privacy,
+	permits = EXCLUDED.permits
+`
+
+func (upr *SQLUserPreferencesRepository) SetUserPreference(preference *model.UserPreference) error {
+    log.Debug().Printf("repo: set user[%s] preference for guild[%s]", preference.UserID, preference.GuildID)
+
+    if _, err := upr.db.Exec(
+        upr.dialect.Query(UpsertUserPreference),
+        preference.UserID,
+        preference.GuildID,
+        preference.Name,
+        preference.UserLimit,
+        preference.Privacy,
+        strings.Join(preference.Permits, ","),
+    ); err != nil {
+        return fmt.Errorf(
+            "repo: unable to set user[%s] preference for guild[%s]: %w",
+            preference.UserID,
+            preference.GuildID,
+            err,
+        )
+    }
+
+    return nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+    "strconv"
+    "strings"
+)
+
+// Dialect is the SQL flavour of the storage backend.
+type Dialect int
+
+const (
+    DialectSQLite   Dialect = iota // SQLite, queries use "?" placeholders
+    DialectPostgres                // PostgreSQL, queries use "$1", "$2", ... placeholders
+)
+
+// Query returns the portable query with placeholders of the dialect.
+// Question marks inside quoted strings, quoted identifiers and comments are kept as they are.
+func (d Dialect) Query(query string) string {
+    if d != DialectPostgres {
+        return query
+    }
+
+    var builder strings.Builder
+    builder.Grow(len(query) + 8)
+
+    var arg int
+    var quote byte // Quote character of the open string or identifier, 0 outside of them
+    for index := 0; index < len(query); index++ {
+        char := query[index]
+
+        switch {
+        case quote != 0:
+            // Doubled quotes escape themselves, they close and reopen the string right away
+            if char == quote {
+                quote = 0
+            }
+        case char == '\'' || char == '"':
+            quote = char
+        case char == '-' && strings.HasPrefix(query[index:], "--"):
+            end := strings.IndexByte(query[index:], '\n')
+            if end < 0 {
+                end = len(query) - index
+            }
+
+            builder.WriteString(query[index : index+end])
+            index += end - 1
+            continue
+        case char == '/' && strings.HasPrefix(query[index:], "/*"):
+            end := strings.Index(query[index:], "*/")
+            if end < 0 {
+                end = len(query) - index
+            } else {
+                end += len("*/")
+            }
+
+            builder.WriteString(query[index : index+end])
+            index += end - 1
+            continue
+        case char == '?':
+            arg++
+            builder.WriteString("$")
+            builder.WriteString(strconv.Itoa(arg))
+            continue
+        }
+
+        builder.WriteByte(char)
+    }
+
+    return builder.String()
+}
+
+// Pick returns the query written for the dialect, for statements SQLite and PostgreSQL do not share.
+func (d Dialect) Pick(sqlite string, postgres string) string {
+    if d == DialectPostgres {
+        return d.Query(postgres)
+    }
+
+    return sqlite
+}
+
+func (d Dialect) String() string {
+    if d == DialectPostgres {
+        return "postgres"
+    }
+
+    return "sqlite"
+}
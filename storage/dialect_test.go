@@ -0,0 +1,54 @@
+package storage
+
+import "testing"
+
+func TestDialectQuery(t *testing.T) {
+    tests := []struct {
+        name  string
+        query string
+        want  string
+    }{
+        {
+            name:  "placeholders",
+            query: "SELECT id FROM lobbies WHERE (id = ? AND guild_id = ?)",
+            want:  "SELECT id FROM lobbies WHERE (id = $1 AND guild_id = $2)",
+        },
+        {
+            name:  "string literal",
+            query: "SELECT id FROM lobbies WHERE template = 'who?' AND id = ?",
+            want:  "SELECT id FROM lobbies WHERE template = 'who?' AND id = $1",
+        },
+        {
+            name:  "escaped quote",
+            query: "SELECT 'it''s ?' FROM lobbies WHERE id = ?",
+            want:  "SELECT 'it''s ?' FROM lobbies WHERE id = $1",
+        },
+        {
+            name:  "quoted identifier",
+            query: `SELECT "odd?" FROM lobbies WHERE id = ?`,
+            want:  `SELECT "odd?" FROM lobbies WHERE id = $1`,
+        },
+        {
+            name:  "comments",
+            query: "SELECT id -- any?\nFROM lobbies /* which? */ WHERE id = ?",
+            want:  "SELECT id -- any?\nFROM lobbies /* which? */ WHERE id = $1",
+        },
+        {
+            name:  "unterminated comment",
+            query: "SELECT ? /* open?",
+            want:  "SELECT $1 /* open?",
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            if got := DialectPostgres.Query(test.query); got != test.want {
+                t.Errorf("Query() = %q, want %q", got, test.want)
+            }
+
+            if got := DialectSQLite.Query(test.query); got != test.query {
+                t.Errorf("SQLite Query() = %q, want the query unchanged", got)
+            }
+        })
+    }
+}
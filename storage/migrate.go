@@ -0,0 +1,289 @@
+package storage
+
+import (
+    "database/sql"
+    "embed"
+    "fmt"
+    "hometown-bot/log"
+    "io/fs"
+    "net/url"
+    "os"
+    "os/exec"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+// migration is an embedded file "<dialect>/<version>_<name>.sql", applied once in the order of versions.
+type migration struct {
+    version int
+    name    string
+    query   string
+}
+
+var (
+    schemaVersionTable = `
+CREATE TABLE IF NOT EXISTS schema_version(
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at BIGINT NOT NULL	/* unix time when the migration was applied */
+);`
+
+    selectSchemaVersion = `
+SELECT coalesce(max(version), 0)
+FROM schema_version`
+
+    insertSchemaVersion = `
+INSERT INTO schema_version (version, name, applied_at)
+VALUES(?, ?, ?)`
+
+    selectTableExistsSQLite = `
+SELECT count(*)
+FROM sqlite_master
+WHERE (type = 'table' AND name = ?)`
+
+    selectColumnExistsSQLite = `
+SELECT count(*)
+FROM pragma_table_info(?)
+WHERE name = ?`
+
+    selectTableExistsPostgres = `
+SELECT count(*)
+FROM information_schema.tables
+WHERE (table_schema = current_schema() AND table_name = ?)`
+)
+
+// addColumnStatement matches a statement of a SQLite migration adding a column, with the table and the column names.
+var addColumnStatement = regexp.MustCompile(`(?im)^ALTER TABLE (\w+) ADD COLUMN (\w+)[^;]*;`)
+
+// Migrate brings the schema of the database to the latest version of its dialect.
+// Every migration runs in its own transaction, the database is backed up before each of them,
+// unless skipBackup is set.
+func Migrate(db *sql.DB, dialect Dialect, source string, skipBackup bool) error {
+    log.Debug().Println("storage: exec schema version table query")
+    if _, err := db.Exec(schemaVersionTable); err != nil {
+        return fmt.Errorf("create schema version table: %w", err)
+    }
+
+    var current int
+    if err := db.QueryRow(selectSchemaVersion).Scan(&current); err != nil {
+        return fmt.Errorf("get schema version: %w", err)
+    }
+
+    // Databases created before migrations have tables of the first release, but no schema version
+    var legacyTables int
+    if err := db.QueryRow(
+        dialect.Pick(selectTableExistsSQLite, selectTableExistsPostgres),
+        "lobbies",
+    ).Scan(&legacyTables); err != nil {
+        return fmt.Errorf("check lobbies table: %w", err)
+    }
+    isFresh := current == 0 && legacyTables == 0
+
+    migrations, err := loadMigrations(dialect)
+    if err != nil {
+        return fmt.Errorf("load migrations: %w", err)
+    }
+
+    for _, m := range migrations {
+        if m.version <= current {
+            continue
+        }
+
+        // Fresh databases have nothing to lose and are not backed up
+        if skipBackup && !isFresh {
+            log.Warn().Printf("storage: backups are skipped, schema version %d is not backed up", current)
+        } else if !isFresh {
+            if err := backup(db, dialect, source, current); err != nil {
+                return fmt.Errorf("backup before migration %d: %w", m.version, err)
+            }
+        }
+
+        log.Info().Printf("storage: apply migration %04d_%s", m.version, m.name)
+        if err := apply(db, dialect, m); err != nil {
+            return fmt.Errorf("apply migration %d: %w", m.version, err)
+        }
+
+        current = m.version
+    }
+
+    log.Info().Printf("storage: schema version %d", current)
+    return nil
+}
+
+func apply(db *sql.DB, dialect Dialect, m migration) error {
+    tx, err := db.Begin()
+    if err != nil {
+        return fmt.Errorf("begin transaction: %w", err)
+    }
+
+    query := m.query
+    if dialect == DialectSQLite {
+        if query, err = skipExistingColumns(tx, query); err != nil {
+            _ = tx.Rollback()
+            return fmt.Errorf("check columns: %w", err)
+        }
+    }
+
+    if _, err := tx.Exec(query); err != nil {
+        _ = tx.Rollback()
+        return fmt.Errorf("exec: %w", err)
+    }
+
+    if _, err := tx.Exec(dialect.Query(insertSchemaVersion), m.version, m.name, time.Now().Unix()); err != nil {
+        _ = tx.Rollback()
+        return fmt.Errorf("set schema version: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("commit transaction: %w", err)
+    }
+
+    return nil
+}
+
+// skipExistingColumns drops statements adding columns that already exist. Development builds before versioned
+// migrations created their tables with the columns of later migrations, SQLite has no "ADD COLUMN IF NOT EXISTS".
+func skipExistingColumns(tx *sql.Tx, query string) (string, error) {
+    var err error
+    query = addColumnStatement.ReplaceAllStringFunc(query, func(statement string) string {
+        match := addColumnStatement.FindStringSubmatch(statement)
+
+        var exists int
+        if scanErr := tx.QueryRow(selectColumnExistsSQLite, match[1], match[2]).Scan(&exists); scanErr != nil {
+            err = fmt.Errorf("column %s.%s: %w", match[1], match[2], scanErr)
+            return statement
+        }
+
+        if exists > 0 {
+            log.Warn().Printf("storage: column %s.%s already exists, skip adding it", match[1], match[2])
+            return ""
+        }
+
+        return statement
+    })
+
+    return query, err
+}
+
+// backup copies a SQLite database into "<source>.v<version>-<unix time>.bak",
+// a PostgreSQL database is dumped by pg_dump into "postgres.v<version>-<unix time>.sql".
+func backup(db *sql.DB, dialect Dialect, source string, version int) error {
+    if dialect == DialectPostgres {
+        backupPath := fmt.Sprintf("postgres.v%d-%d.sql", version, time.Now().Unix())
+
+        if _, err := exec.LookPath("pg_dump"); err != nil {
+            return fmt.Errorf("pg_dump is not installed, install it or run with --skip-backup: %w", err)
+        }
+
+        environment, err := pgEnvironment(source)
+        if err != nil {
+            return fmt.Errorf("pg_dump: %w", err)
+        }
+
+        // Connection settings are passed in the environment, so the password does not show up in the process list
+        log.Info().Printf("storage: back up schema version %d to %s", version, backupPath)
+        command := exec.Command("pg_dump", "--file", backupPath)
+        command.Env = append(os.Environ(), environment...)
+        if output, err := command.CombinedOutput(); err != nil {
+            return fmt.Errorf("pg_dump: %w: %s", err, output)
+        }
+
+        return nil
+    }
+
+    backupPath := fmt.Sprintf("%s.v%d-%d.bak", source, version, time.Now().Unix())
+    log.Info().Printf("storage: back up schema version %d to %s", version, backupPath)
+
+    if _, err := db.Exec("VACUUM INTO ?", backupPath); err != nil {
+        return fmt.Errorf("vacuum into %s: %w", backupPath, err)
+    }
+
+    return nil
+}
+
+// pgEnvironment converts the PostgreSQL DSN into the PG* variables of libpq tools.
+func pgEnvironment(dsn string) ([]string, error) {
+    u, err := url.Parse(dsn)
+    if err != nil {
+        return nil, fmt.Errorf("parse DSN: %w", err)
+    }
+
+    variables := map[string]string{
+        "PGHOST":     u.Hostname(),
+        "PGPORT":     u.Port(),
+        "PGUSER":     u.User.Username(),
+        "PGDATABASE": strings.TrimPrefix(u.Path, "/"),
+    }
+
+    if password, ok := u.User.Password(); ok {
+        variables["PGPASSWORD"] = password
+    }
+
+    parameters := map[string]string{
+        "sslmode":         "PGSSLMODE",
+        "sslrootcert":     "PGSSLROOTCERT",
+        "sslcert":         "PGSSLCERT",
+        "sslkey":          "PGSSLKEY",
+        "connect_timeout": "PGCONNECT_TIMEOUT",
+    }
+    for parameter, variable := range parameters {
+        variables[variable] = u.Query().Get(parameter)
+    }
+
+    var environment []string
+    for variable, value := range variables {
+        if value != "" {
+            environment = append(environment, fmt.Sprintf("%s=%s", variable, value))
+        }
+    }
+
+    return environment, nil
+}
+
+func loadMigrations(dialect Dialect) ([]migration, error) {
+    files, err := fs.Glob(migrationFiles, fmt.Sprintf("migrations/%s/*.sql", dialect))
+    if err != nil {
+        return nil, err
+    }
+
+    migrations := make([]migration, 0, len(files))
+    versions := make(map[int]string, len(files))
+    for _, file := range files {
+        base := strings.TrimSuffix(path.Base(file), ".sql")
+
+        prefix, name, ok := strings.Cut(base, "_")
+        if !ok {
+            return nil, fmt.Errorf("migration %s has no \"<version>_<name>\" format", file)
+        }
+
+        version, err := strconv.Atoi(prefix)
+        if err != nil || version <= 0 {
+            return nil, fmt.Errorf("migration %s has invalid version %q", file, prefix)
+        }
+
+        if other, ok := versions[version]; ok {
+            return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+        }
+        versions[version] = file
+
+        query, err := migrationFiles.ReadFile(file)
+        if err != nil {
+            return nil, err
+        }
+
+        migrations = append(migrations, migration{version: version, name: name, query: string(query)})
+    }
+
+    sort.Slice(migrations, func(a, b int) bool {
+        return migrations[a].version < migrations[b].version
+    })
+
+    return migrations, nil
+}
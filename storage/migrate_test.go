@@ -0,0 +1,147 @@
+package storage
+
+import (
+    "database/sql"
+    "path/filepath"
+    "testing"
+)
+
+// developmentSchema is the schema created by development builds right before versioned migrations,
+// its tables already have the columns that the migrations add.
+const developmentSchema = `
+CREATE TABLE IF NOT EXISTS lobbies(
+	id TEXT PRIMARY KEY,
+	category_id TEXT, 			/* immutable */
+	guild_id TEXT, 				/* immutable */
+	template TEXT,				/* mutable, default NULL */
+	capacity INTEGER,			/* mutable, default NULL */
+	naming INTEGER,				/* mutable, default NULL, 0 - template, 1 - numbered */
+	grace_period INTEGER,		/* mutable, default NULL, seconds before empty channel is deleted */
+	bitrate INTEGER,			/* mutable, default NULL, bits per second */
+	rtc_region TEXT,			/* mutable, default NULL, automatic if empty */
+	video_quality INTEGER,		/* mutable, default NULL, 1 - auto, 2 - full */
+	nsfw INTEGER,				/* mutable, default NULL */
+	text_channel INTEGER,		/* mutable, default NULL, 0 - none, 1 - delete, 2 - archive */
+	activity INTEGER,			/* mutable, default NULL, rename channels after the game */
+	overrides INTEGER,			/* mutable, default NULL, apply user preferences to new channels */
+	cooldown INTEGER,			/* mutable, default NULL, seconds between two channels of the same user */
+	block_spam INTEGER,			/* mutable, default NULL, temporarily block repeat offenders */
+	max_rooms INTEGER,			/* mutable, default NULL, unlimited if 0 */
+	waiting_id TEXT,			/* mutable, default NULL, no waiting room if empty */
+	permissions INTEGER,		/* mutable, default NULL, 0 - category, 1 - lobby, 2 - custom */
+	placement INTEGER,			/* mutable, default NULL, 0 - below lobby, 1 - bottom, 2 - by index */
+	auto_overflow INTEGER		/* mutable, default NULL, create overflow categories when all are full */
+);
+CREATE TABLE IF NOT EXISTS lobby_overflows(
+	lobby_id TEXT NOT NULL,
+	category_id TEXT NOT NULL,
+	position INTEGER NOT NULL,	/* order in which overflow categories are used */
+	PRIMARY KEY (lobby_id, category_id)
+);
+CREATE TABLE IF NOT EXISTS lobby_overwrites(
+	lobby_id TEXT NOT NULL,
+	target_id TEXT NOT NULL,
+	type INTEGER NOT NULL,		/* 0 - role, 1 - member */
+	allow INTEGER DEFAULT 0,
+	deny INTEGER DEFAULT 0,
+	PRIMARY KEY (lobby_id, target_id)
+);
+CREATE TABLE IF NOT EXISTS lobby_roles(
+	lobby_id TEXT NOT NULL,
+	role_id TEXT NOT NULL,
+	allowed INTEGER NOT NULL,	/* 1 - allowed, 0 - denied */
+	PRIMARY KEY (lobby_id, role_id)
+);
+CREATE TABLE IF NOT EXISTS guilds(
+	id TEXT PRIMARY KEY,
+	max_rooms INTEGER			/* mutable, default NULL, unlimited if 0 */
+);
+CREATE TABLE IF NOT EXISTS channels(
+	id TEXT PRIMARY KEY,
+	parent_id TEXT NOT NULL,	/* immutable */
+	owner_id TEXT,				/* mutable */
+	privacy INTEGER DEFAULT 0,	/* mutable, 0 - open, 1 - locked, 2 - hidden */
+	idx INTEGER,				/* immutable, number of the channel within its lobby */
+	delete_at INTEGER,			/* mutable, unix time of the pending deletion */
+	text_id TEXT				/* immutable, companion text channel */
+);
+CREATE UNIQUE INDEX IF NOT EXISTS channels_parent_idx
+ON channels(parent_id, idx);
+CREATE TABLE IF NOT EXISTS channel_permits(
+	channel_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	allowed INTEGER NOT NULL,	/* 1 - permitted, 0 - denied */
+	PRIMARY KEY (channel_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS user_preferences(
+	user_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	user_limit INTEGER DEFAULT 0,	/* 0 - unlimited */
+	privacy INTEGER DEFAULT 0,		/* 0 - open, 1 - locked, 2 - hidden */
+	permits TEXT,					/* comma separated ids of permitted users */
+	PRIMARY KEY (user_id, guild_id)
+);
+CREATE TABLE IF NOT EXISTS room_bans(
+	owner_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,		/* banned from all rooms of the owner */
+	PRIMARY KEY (owner_id, guild_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS channel_members(
+	user_id TEXT PRIMARY KEY,
+	channel_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	joined_at INTEGER			/* unix time when user joined the channel */
+);
+`
+
+func TestMigrateDevelopmentSchema(t *testing.T) {
+    source := filepath.Join(t.TempDir(), "storage.db")
+
+    db, err := sql.Open("sqlite3", source)
+    if err != nil {
+        t.Fatalf("open sql: %v", err)
+    }
+    defer db.Close()
+
+    if _, err := db.Exec(developmentSchema); err != nil {
+        t.Fatalf("create development schema: %v", err)
+    }
+
+    if _, err := db.Exec(`
+INSERT INTO lobbies (id, category_id, guild_id, cooldown) VALUES ('lobby', 'category', 'guild', 30);
+INSERT INTO channels (id, parent_id, owner_id, idx) VALUES ('room', 'lobby', 'owner', 1);
+INSERT INTO channel_members (user_id, channel_id, guild_id, joined_at) VALUES ('user', 'room', 'guild', 1);
+`); err != nil {
+        t.Fatalf("insert rows: %v", err)
+    }
+
+    if err := Migrate(db, DialectSQLite, source, false); err != nil {
+        t.Fatalf("Migrate() error = %v", err)
+    }
+
+    migrations, err := loadMigrations(DialectSQLite)
+    if err != nil {
+        t.Fatalf("load migrations: %v", err)
+    }
+
+    var version int
+    if err := db.QueryRow(selectSchemaVersion).Scan(&version); err != nil {
+        t.Fatalf("get schema version: %v", err)
+    }
+    if want := migrations[len(migrations)-1].version; version != want {
+        t.Fatalf("schema version = %d, want %d", version, want)
+    }
+
+    var cooldown int
+    var guildId string
+    if err := db.QueryRow(`
+SELECT lobbies.cooldown, channels.guild_id
+FROM lobbies JOIN channels ON channels.parent_id = lobbies.id`).Scan(&cooldown, &guildId); err != nil {
+        t.Fatalf("get migrated rows: %v", err)
+    }
+    if cooldown != 30 || guildId != "guild" {
+        t.Fatalf("migrated rows = %d, %s, want 30, guild", cooldown, guildId)
+    }
+}
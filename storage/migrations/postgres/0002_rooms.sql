@@ -0,0 +1,34 @@
+ALTER TABLE channels ADD COLUMN owner_id TEXT;					/* mutable */
+ALTER TABLE channels ADD COLUMN privacy INTEGER DEFAULT 0;		/* mutable, 0 - open, 1 - locked, 2 - hidden */
+ALTER TABLE channels ADD COLUMN idx INTEGER;					/* immutable, number of the channel within its lobby */
+ALTER TABLE channels ADD COLUMN delete_at BIGINT;					/* mutable, unix time of the pending deletion */
+ALTER TABLE channels ADD COLUMN text_id TEXT;					/* immutable, companion text channel */
+
+CREATE UNIQUE INDEX IF NOT EXISTS channels_parent_idx
+ON channels(parent_id, idx);
+
+ALTER TABLE channel_members ADD COLUMN joined_at BIGINT;			/* unix time when user joined the channel */
+
+CREATE TABLE IF NOT EXISTS channel_permits(
+	channel_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	allowed BOOLEAN NOT NULL,	/* permitted or denied */
+	PRIMARY KEY (channel_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_preferences(
+	user_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	user_limit INTEGER DEFAULT 0,	/* 0 - unlimited */
+	privacy INTEGER DEFAULT 0,		/* 0 - open, 1 - locked, 2 - hidden */
+	permits TEXT,					/* comma separated ids of permitted users */
+	PRIMARY KEY (user_id, guild_id)
+);
+
+CREATE TABLE IF NOT EXISTS room_bans(
+	owner_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,		/* banned from all rooms of the owner */
+	PRIMARY KEY (owner_id, guild_id, user_id)
+);
@@ -0,0 +1,44 @@
+ALTER TABLE lobbies ADD COLUMN naming INTEGER;				/* mutable, default NULL, 0 - template, 1 - numbered */
+ALTER TABLE lobbies ADD COLUMN grace_period INTEGER;		/* mutable, default NULL, seconds before empty channel is deleted */
+ALTER TABLE lobbies ADD COLUMN bitrate INTEGER;			/* mutable, default NULL, bits per second */
+ALTER TABLE lobbies ADD COLUMN rtc_region TEXT;			/* mutable, default NULL, automatic if empty */
+ALTER TABLE lobbies ADD COLUMN video_quality INTEGER;		/* mutable, default NULL, 1 - auto, 2 - full */
+ALTER TABLE lobbies ADD COLUMN nsfw BOOLEAN;				/* mutable, default NULL */
+ALTER TABLE lobbies ADD COLUMN text_channel INTEGER;		/* mutable, default NULL, 0 - none, 1 - delete, 2 - archive */
+ALTER TABLE lobbies ADD COLUMN activity BOOLEAN;			/* mutable, default NULL, rename channels after the game */
+ALTER TABLE lobbies ADD COLUMN overrides BOOLEAN;			/* mutable, default NULL, apply user preferences to new channels */
+ALTER TABLE lobbies ADD COLUMN cooldown INTEGER;			/* mutable, default NULL, seconds between two channels of the same user */
+ALTER TABLE lobbies ADD COLUMN block_spam BOOLEAN;			/* mutable, default NULL, temporarily block repeat offenders */
+ALTER TABLE lobbies ADD COLUMN max_rooms INTEGER;			/* mutable, default NULL, unlimited if 0 */
+ALTER TABLE lobbies ADD COLUMN waiting_id TEXT;			/* mutable, default NULL, no waiting room if empty */
+ALTER TABLE lobbies ADD COLUMN permissions INTEGER;		/* mutable, default NULL, 0 - category, 1 - lobby, 2 - custom */
+ALTER TABLE lobbies ADD COLUMN placement INTEGER;			/* mutable, default NULL, 0 - below lobby, 1 - bottom, 2 - by index */
+ALTER TABLE lobbies ADD COLUMN auto_overflow BOOLEAN;		/* mutable, default NULL, create overflow categories when all are full */
+
+CREATE TABLE IF NOT EXISTS lobby_roles(
+	lobby_id TEXT NOT NULL,
+	role_id TEXT NOT NULL,
+	allowed BOOLEAN NOT NULL,	/* allowed or denied */
+	PRIMARY KEY (lobby_id, role_id)
+);
+
+CREATE TABLE IF NOT EXISTS lobby_overwrites(
+	lobby_id TEXT NOT NULL,
+	target_id TEXT NOT NULL,
+	type INTEGER NOT NULL,		/* 0 - role, 1 - member */
+	allow BIGINT DEFAULT 0,
+	deny BIGINT DEFAULT 0,
+	PRIMARY KEY (lobby_id, target_id)
+);
+
+CREATE TABLE IF NOT EXISTS lobby_overflows(
+	lobby_id TEXT NOT NULL,
+	category_id TEXT NOT NULL,
+	position INTEGER NOT NULL,	/* order in which overflow categories are used */
+	PRIMARY KEY (lobby_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS guilds(
+	id TEXT PRIMARY KEY,
+	max_rooms INTEGER			/* mutable, default NULL, unlimited if 0 */
+);
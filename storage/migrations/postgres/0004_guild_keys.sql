@@ -0,0 +1,27 @@
+/* Rooms and their members are scoped to guilds, a user can be connected on several guilds at once */
+ALTER TABLE channels ADD COLUMN guild_id TEXT;					/* immutable */
+
+UPDATE channels
+SET guild_id = lobbies.guild_id
+FROM lobbies
+WHERE (channels.guild_id IS NULL AND lobbies.id = channels.parent_id);
+
+UPDATE channels
+SET guild_id = channel_members.guild_id
+FROM channel_members
+WHERE (channels.guild_id IS NULL AND channel_members.channel_id = channels.id);
+
+/* Empty rooms of removed lobbies cannot be traced back to their guild */
+DELETE FROM channels
+WHERE guild_id IS NULL;
+
+DELETE FROM channel_permits
+WHERE channel_id NOT IN (SELECT id FROM channels);
+
+ALTER TABLE channels ALTER COLUMN guild_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS channels_guild
+ON channels(guild_id);
+
+ALTER TABLE channel_members DROP CONSTRAINT channel_members_pkey;
+ALTER TABLE channel_members ADD PRIMARY KEY (guild_id, user_id);
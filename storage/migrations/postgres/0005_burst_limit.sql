@@ -0,0 +1,2 @@
+/* Burst limit of room creation is configured per lobby instead of being fixed */
+ALTER TABLE lobbies ADD COLUMN burst_limit INTEGER;		/* mutable, default NULL, rooms a user can create within 5 minutes, unlimited if 0 */
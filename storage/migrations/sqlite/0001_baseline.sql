@@ -0,0 +1,19 @@
+/* Schema of the first release, databases created before migrations start from it */
+CREATE TABLE IF NOT EXISTS lobbies(
+	id TEXT PRIMARY KEY,
+	category_id TEXT, 			/* immutable */
+	guild_id TEXT, 				/* immutable */
+	template TEXT,				/* mutable, default NULL */
+	capacity INTEGER			/* mutable, default NULL */
+);
+
+CREATE TABLE IF NOT EXISTS channels(
+	id TEXT PRIMARY KEY,
+	parent_id TEXT NOT NULL		/* immutable */
+);
+
+CREATE TABLE IF NOT EXISTS channel_members(
+	user_id TEXT PRIMARY KEY,
+	channel_id TEXT NOT NULL,
+	guild_id TEXT NOT NULL
+);
@@ -0,0 +1,59 @@
+/* Rooms and their members are scoped to guilds, a user can be connected on several guilds at once */
+ALTER TABLE channels ADD COLUMN guild_id TEXT;					/* immutable */
+
+UPDATE channels
+SET guild_id = (SELECT lobbies.guild_id FROM lobbies WHERE lobbies.id = channels.parent_id)
+WHERE guild_id IS NULL;
+
+UPDATE channels
+SET guild_id = (SELECT channel_members.guild_id FROM channel_members WHERE channel_members.channel_id = channels.id LIMIT 1)
+WHERE guild_id IS NULL;
+
+/* Empty rooms of removed lobbies cannot be traced back to their guild */
+DELETE FROM channels
+WHERE guild_id IS NULL;
+
+DELETE FROM channel_permits
+WHERE channel_id NOT IN (SELECT id FROM channels);
+
+/* SQLite cannot add NOT NULL to an existing column, the table is rebuilt with the resolved guilds */
+CREATE TABLE channels_guild(
+	id TEXT PRIMARY KEY,
+	parent_id TEXT NOT NULL,		/* immutable */
+	guild_id TEXT NOT NULL,			/* immutable */
+	owner_id TEXT,					/* mutable */
+	privacy INTEGER DEFAULT 0,		/* mutable, 0 - open, 1 - locked, 2 - hidden */
+	idx INTEGER,					/* immutable, number of the channel within its lobby */
+	delete_at INTEGER,				/* mutable, unix time of the pending deletion */
+	text_id TEXT					/* immutable, companion text channel */
+);
+
+INSERT INTO channels_guild (id, parent_id, guild_id, owner_id, privacy, idx, delete_at, text_id)
+SELECT id, parent_id, guild_id, owner_id, privacy, idx, delete_at, text_id
+FROM channels;
+
+DROP TABLE channels;
+
+ALTER TABLE channels_guild RENAME TO channels;
+
+CREATE UNIQUE INDEX IF NOT EXISTS channels_parent_idx
+ON channels(parent_id, idx);
+
+CREATE INDEX IF NOT EXISTS channels_guild
+ON channels(guild_id);
+
+CREATE TABLE channel_members_guild(
+	guild_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	channel_id TEXT NOT NULL,
+	joined_at INTEGER,			/* unix time when user joined the channel */
+	PRIMARY KEY (guild_id, user_id)
+);
+
+INSERT INTO channel_members_guild (guild_id, user_id, channel_id, joined_at)
+SELECT guild_id, user_id, channel_id, joined_at
+FROM channel_members;
+
+DROP TABLE channel_members;
+
+ALTER TABLE channel_members_guild RENAME TO channel_members;
@@ -4,64 +4,45 @@ import (
     "database/sql"
     "fmt"
     "hometown-bot/log"
+    "strings"
 
+    _ "github.com/lib/pq"
     _ "github.com/mattn/go-sqlite3"
 )
 
-var (
-    lobbyTable = `
-CREATE TABLE IF NOT EXISTS lobbies(
-	id TEXT PRIMARY KEY,
-	category_id TEXT, 			/* immutable */
-	guild_id TEXT, 				/* immutable */
-	template TEXT,				/* mutable, default NULL */
-	capacity INTEGER			/* mutable, default NULL */
-);`
+const DefaultDSN = "./storage.db" // SQLite database next to the binary
 
-    channelTable = `
-CREATE TABLE IF NOT EXISTS channels(
-	id TEXT PRIMARY KEY,
-	parent_id TEXT NOT NULL		/* immutable */
-);`
+// Load opens the storage described by dsn and migrates its schema, skipBackup migrates without backups.
+// "postgres://" and "postgresql://" DSNs select PostgreSQL, anything else is a path to the SQLite database,
+// optionally prefixed with "sqlite://".
+func Load(dsn string, skipBackup bool) (*sql.DB, Dialect, error) {
+    driver, source, dialect := parseDSN(dsn)
 
-    channelMembersTable = `
-CREATE TABLE IF NOT EXISTS channel_members(
-	user_id TEXT PRIMARY KEY,
-	channel_id TEXT NOT NULL,
-	guild_id TEXT NOT NULL
-);`
-)
-
-func Load() (*sql.DB, error) {
-    log.Debug().Println("storage: trying to open SQLite connection")
-    db, err := sql.Open("sqlite3", "./storage.db")
+    log.Debug().Printf("storage: trying to open %s connection", dialect)
+    db, err := sql.Open(driver, source)
     if err != nil {
-        return nil, fmt.Errorf("open sql: %w", err)
+        return nil, dialect, fmt.Errorf("open sql: %w", err)
     }
 
-    log.Debug().Println("storage: exec lobby table query")
-    _, err = db.Exec(lobbyTable)
+    log.Debug().Println("storage: verify DB connection")
+    err = db.Ping()
     if err != nil {
-        return nil, fmt.Errorf("create lobby table: %w", err)
+        return nil, dialect, fmt.Errorf("verify db connection: %w", err)
     }
 
-    log.Debug().Println("storage: exec channel table query")
-    _, err = db.Exec(channelTable)
+    log.Debug().Println("storage: migrate schema")
+    err = Migrate(db, dialect, source, skipBackup)
     if err != nil {
-        return nil, fmt.Errorf("create channel table: %w", err)
+        return nil, dialect, fmt.Errorf("migrate: %w", err)
     }
 
-    log.Debug().Println("storage: exec channel members table query")
-    _, err = db.Exec(channelMembersTable)
-    if err != nil {
-        return nil, fmt.Errorf("create channel members table: %w", err)
-    }
+    return db, dialect, nil
+}
 
-    log.Debug().Println("storage: verify DB connection")
-    err = db.Ping()
-    if err != nil {
-        return nil, fmt.Errorf("verify db connection: %w", err)
+func parseDSN(dsn string) (string, string, Dialect) {
+    if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+        return "postgres", dsn, DialectPostgres
     }
 
-    return db, nil
+    return "sqlite3", strings.TrimPrefix(dsn, "sqlite://"), DialectSQLite
 }
@@ -0,0 +1,54 @@
+package discord
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// Video quality modes of voice channels
+const (
+    VideoQualityAuto int = 1 // Discord chooses the quality for optimal performance
+    VideoQualityFull int = 2 // 720p
+)
+
+const MinBitrate int = 8000 // Lowest bitrate of a voice channel
+
+// VoiceChannelCreateData extends [discordgo.GuildChannelCreateData] with voice settings it does not support.
+type VoiceChannelCreateData struct {
+    discordgo.GuildChannelCreateData
+    RTCRegion        string `json:"rtc_region,omitempty"`
+    VideoQualityMode int    `json:"video_quality_mode,omitempty"`
+}
+
+// CreateVoiceChannel creates a new voice channel in the given guild.
+func CreateVoiceChannel(s *discordgo.Session, guildId string, data VoiceChannelCreateData) (*discordgo.Channel, error) {
+    endpoint := discordgo.EndpointGuildChannels(guildId)
+
+    body, err := s.RequestWithBucketID("POST", endpoint, data, endpoint)
+    if err != nil {
+        return nil, err
+    }
+
+    var channel discordgo.Channel
+    if err := json.Unmarshal(body, &channel); err != nil {
+        return nil, fmt.Errorf("unable to parse created channel: %w", err)
+    }
+
+    return &channel, nil
+}
+
+// MaxBitrate returns the highest voice channel bitrate available for the guild boost tier.
+func MaxBitrate(tier discordgo.PremiumTier) int {
+    switch tier {
+    case discordgo.PremiumTier1:
+        return 128000
+    case discordgo.PremiumTier2:
+        return 256000
+    case discordgo.PremiumTier3:
+        return 384000
+    default:
+        return 96000
+    }
+}
@@ -0,0 +1,172 @@
+package discord
+
+import (
+    "fmt"
+
+    "github.com/bwmarrin/discordgo"
+)
+
+// EditOverwrite adds allow and deny bits to the existing permission overwrite of the target.
+// Bits granted by allow are removed from the deny list and vice versa.
+func EditOverwrite(
+    s *discordgo.Session,
+    channelId string,
+    targetId string,
+    targetType discordgo.PermissionOverwriteType,
+    allow int64,
+    deny int64,
+) error {
+    current := findOverwrite(s, channelId, targetId)
+
+    newAllow := (current.Allow &^ deny) | allow
+    newDeny := (current.Deny &^ allow) | deny
+    if newAllow == current.Allow && newDeny == current.Deny {
+        return nil
+    }
+
+    if err := s.ChannelPermissionSet(channelId, targetId, targetType, newAllow, newDeny); err != nil {
+        return fmt.Errorf("unable to set permissions for %s in channel %s: %w", targetId, channelId, err)
+    }
+
+    return nil
+}
+
+// DenyOverwrite denies bits to the target and withdraws revoked bits it was allowed before, in a single update.
+func DenyOverwrite(
+    s *discordgo.Session,
+    channelId string,
+    targetId string,
+    targetType discordgo.PermissionOverwriteType,
+    revoke int64,
+    deny int64,
+) error {
+    current := findOverwrite(s, channelId, targetId)
+
+    newAllow := current.Allow &^ (revoke | deny)
+    newDeny := (current.Deny &^ revoke) | deny
+    if newAllow == current.Allow && newDeny == current.Deny {
+        return nil
+    }
+
+    if err := s.ChannelPermissionSet(channelId, targetId, targetType, newAllow, newDeny); err != nil {
+        return fmt.Errorf("unable to set permissions for %s in channel %s: %w", targetId, channelId, err)
+    }
+
+    return nil
+}
+
+// ClearOverwrite removes bits from the existing permission overwrite of the target.
+// The overwrite is deleted completely once it has nothing left.
+func ClearOverwrite(
+    s *discordgo.Session,
+    channelId string,
+    targetId string,
+    targetType discordgo.PermissionOverwriteType,
+    bits int64,
+) error {
+    current := findOverwrite(s, channelId, targetId)
+
+    newAllow := current.Allow &^ bits
+    newDeny := current.Deny &^ bits
+
+    if newAllow == 0 && newDeny == 0 {
+        if err := s.ChannelPermissionDelete(channelId, targetId); err != nil {
+            return fmt.Errorf("unable to delete permissions for %s in channel %s: %w", targetId, channelId, err)
+        }
+
+        return nil
+    }
+
+    if err := s.ChannelPermissionSet(channelId, targetId, targetType, newAllow, newDeny); err != nil {
+        return fmt.Errorf("unable to set permissions for %s in channel %s: %w", targetId, channelId, err)
+    }
+
+    return nil
+}
+
+// MoveOverwrite transfers the member permission overwrite from one member to another.
+func MoveOverwrite(s *discordgo.Session, channelId string, fromId string, toId string) error {
+    current := findOverwrite(s, channelId, fromId)
+    if current.Allow == 0 && current.Deny == 0 {
+        return nil
+    }
+
+    if err := EditOverwrite(
+        s,
+        channelId,
+        toId,
+        discordgo.PermissionOverwriteTypeMember,
+        current.Allow,
+        current.Deny,
+    ); err != nil {
+        return err
+    }
+
+    if err := s.ChannelPermissionDelete(channelId, fromId); err != nil {
+        return fmt.Errorf("unable to delete permissions for %s in channel %s: %w", fromId, channelId, err)
+    }
+
+    return nil
+}
+
+func findOverwrite(s *discordgo.Session, channelId string, targetId string) discordgo.PermissionOverwrite {
+    channel, err := s.State.Channel(channelId)
+    if err != nil {
+        channel, err = s.Channel(channelId)
+        if err != nil {
+            return discordgo.PermissionOverwrite{}
+        }
+    }
+
+    for _, overwrite := range channel.PermissionOverwrites {
+        if overwrite.ID == targetId {
+            return *overwrite
+        }
+    }
+
+    return discordgo.PermissionOverwrite{}
+}
+
+// MergeOverwrite adds allow and deny bits to the overwrite of the target in the list, the same way as EditOverwrite.
+// The overwrite is appended if the target has none yet.
+func MergeOverwrite(
+    overwrites []*discordgo.PermissionOverwrite,
+    targetId string,
+    targetType discordgo.PermissionOverwriteType,
+    allow int64,
+    deny int64,
+) []*discordgo.PermissionOverwrite {
+    for _, overwrite := range overwrites {
+        if overwrite.ID == targetId {
+            overwrite.Allow = (overwrite.Allow &^ deny) | allow
+            overwrite.Deny = (overwrite.Deny &^ allow) | deny
+            return overwrites
+        }
+    }
+
+    return append(overwrites, &discordgo.PermissionOverwrite{
+        ID:    targetId,
+        Type:  targetType,
+        Allow: allow,
+        Deny:  deny,
+    })
+}
+
+// CopyOverwrites returns copies of the permission overwrites of the channel.
+func CopyOverwrites(s *discordgo.Session, channelId string) ([]*discordgo.PermissionOverwrite, error) {
+    channel, err := s.State.Channel(channelId)
+    if err != nil {
+        channel, err = s.Channel(channelId)
+        if err != nil {
+            return nil, fmt.Errorf("unable to get channel %s: %w", channelId, err)
+        }
+    }
+
+    overwrites := make([]*discordgo.PermissionOverwrite, 0, len(channel.PermissionOverwrites))
+    for _, overwrite := range channel.PermissionOverwrites {
+        copied := *overwrite
+        overwrites = append(overwrites, &copied)
+    }
+
+    return overwrites, nil
+}
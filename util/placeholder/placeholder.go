@@ -0,0 +1,102 @@
+package placeholder
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Supported placeholders
+const (
+    Username    string = "%username%"    // Discord username
+    Nickname    string = "%nickname%"    // Server nickname or username if it is not set
+    DisplayName string = "%displayname%" // Server nickname, global name or username
+    Game        string = "%game%"        // Game from the user presence
+    Index       string = "%index%"       // Number of the room within its lobby
+    Lobby       string = "%lobby%"       // Lobby channel name
+    Count       string = "%count%"       // Active rooms of the lobby
+)
+
+const maxLength = 100 // Discord channel name limit
+
+var (
+    tokenPattern = regexp.MustCompile(`%[a-zA-Z_]+%`)
+    spaces       = regexp.MustCompile(`\s+`)
+    Supported    = []string{Username, Nickname, DisplayName, Game, Index, Lobby, Count}
+)
+
+type Values struct {
+    Username    string
+    Nickname    string
+    DisplayName string
+    Game        string
+    Index       int
+    Lobby       string
+    Count       int
+}
+
+// Validate returns an error listing all unknown placeholders of the template.
+func Validate(template string) error {
+    var unknown []string
+    for _, token := range tokenPattern.FindAllString(template, -1) {
+        if !isSupported(token) {
+            unknown = append(unknown, token)
+        }
+    }
+
+    if len(unknown) > 0 {
+        return fmt.Errorf("unknown placeholders: %s", strings.Join(unknown, ", "))
+    }
+
+    return nil
+}
+
+// HasPlaceholders reports whether the template contains at least one known placeholder.
+func HasPlaceholders(template string) bool {
+    for _, token := range tokenPattern.FindAllString(template, -1) {
+        if isSupported(token) {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Render replaces known placeholders of the template, unknown ones are left as is.
+func Render(template string, values Values) string {
+    replacements := map[string]string{
+        Username:    values.Username,
+        Nickname:    values.Nickname,
+        DisplayName: values.DisplayName,
+        Game:        values.Game,
+        Index:       strconv.Itoa(values.Index),
+        Lobby:       values.Lobby,
+        Count:       strconv.Itoa(values.Count),
+    }
+
+    output := tokenPattern.ReplaceAllStringFunc(template, func(token string) string {
+        if value, ok := replacements[strings.ToLower(token)]; ok {
+            return value
+        }
+
+        return token
+    })
+
+    output = strings.TrimSpace(spaces.ReplaceAllString(output, " "))
+    if runes := []rune(output); len(runes) > maxLength {
+        output = strings.TrimSpace(string(runes[:maxLength]))
+    }
+
+    return output
+}
+
+func isSupported(token string) bool {
+    for _, supported := range Supported {
+        if strings.EqualFold(token, supported) {
+            return true
+        }
+    }
+
+    return false
+}
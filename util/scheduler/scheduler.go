@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+    "sync"
+    "time"
+)
+
+// Scheduler runs delayed tasks identified by a key, a task can be cancelled until it is started.
+type Scheduler struct {
+    mu     sync.Mutex
+    timers map[string]*time.Timer
+}
+
+func New() *Scheduler {
+    return &Scheduler{
+        timers: make(map[string]*time.Timer),
+    }
+}
+
+// Schedule runs the task after delay, a task already scheduled for the key is replaced.
+func (s *Scheduler) Schedule(key string, delay time.Duration, task func()) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if timer, ok := s.timers[key]; ok {
+        timer.Stop()
+    }
+
+    var timer *time.Timer
+    timer = time.AfterFunc(delay, func() {
+        s.mu.Lock()
+        if s.timers[key] != timer {
+            s.mu.Unlock()
+            return
+        }
+        delete(s.timers, key)
+        s.mu.Unlock()
+
+        task()
+    })
+
+    s.timers[key] = timer
+}
+
+// Cancel stops the task scheduled for the key, returns false if there was nothing to cancel.
+func (s *Scheduler) Cancel(key string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    timer, ok := s.timers[key]
+    if !ok {
+        return false
+    }
+
+    timer.Stop()
+    delete(s.timers, key)
+    return true
+}
+
+// Has reports whether a task is scheduled for the key.
+func (s *Scheduler) Has(key string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    _, ok := s.timers[key]
+    return ok
+}